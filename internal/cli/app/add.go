@@ -0,0 +1,212 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/contracts/iapplication"
+	"github.com/ZzzzHui/espresso-reader/pkg/contracts/iconsensus"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <address>",
+	Short: "Registers an application",
+	Long: `Registers an application.
+
+The application is checked against the chain before it is registered: its consensus
+must be the one set by --consensus, and its template hash and epoch length are read
+from its contracts.`,
+	Example: `espresso-reader app add 0x2E663fe9aE92275242406A185AA4fC8174339D3E \
+	--consensus 0x5b7F9D0F7D1E4a1C5b2e8b8a53dC2A9e0F4bE1b7 --template-uri /var/lib/snapshots/app`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
+}
+
+var (
+	blockchainHttpEndpoint string
+	consensus              string
+	templateHash           string
+	templateUri            string
+	lastProcessedBlock     uint64
+	disabled               bool
+)
+
+func init() {
+	addCmd.Flags().StringVar(&blockchainHttpEndpoint, "blockchain-http-endpoint", "",
+		"Blockchain HTTP endpoint (default: CARTESI_BLOCKCHAIN_HTTP_ENDPOINT)")
+	addCmd.Flags().StringVar(&consensus, "consensus", "", "Address of the consensus of the application")
+	addCmd.Flags().StringVar(&templateHash, "template-hash", "",
+		"Expected template hash of the application (default: read from the chain)")
+	addCmd.Flags().StringVar(&templateUri, "template-uri", "", "URI of the template of the application")
+	addCmd.Flags().Uint64Var(&lastProcessedBlock, "last-processed-block", 0,
+		"Block after which inputs are read. Blocks before the InputBox deployment are always skipped")
+	addCmd.Flags().BoolVar(&disabled, "disabled", false, "Register the application as not running")
+
+	cobra.CheckErr(addCmd.MarkFlagRequired("consensus"))
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	address, err := parseAddress("application", args[0])
+	if err != nil {
+		return err
+	}
+	consensusAddress, err := parseAddress("--consensus", consensus)
+	if err != nil {
+		return err
+	}
+	var expectedTemplateHash *Hash
+	if templateHash != "" {
+		bytes, err := hexutil.Decode(templateHash)
+		if err != nil || len(bytes) != HashLength {
+			return fmt.Errorf("--template-hash must be a 32-byte hash, got '%s'", templateHash)
+		}
+		hash := common.BytesToHash(bytes)
+		expectedTemplateHash = &hash
+	}
+	endpoint, err := cliutil.BlockchainHttpEndpoint(blockchainHttpEndpoint)
+	if err != nil {
+		return err
+	}
+
+	return withRepository(cmd, func(ctx context.Context, repo applicationRepository) error {
+		existing, err := repo.GetApplication(ctx, address)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("application %s is already registered", address)
+		}
+
+		contracts, close, err := dialContracts(ctx, endpoint)
+		if err != nil {
+			return fmt.Errorf("failed to connect to the blockchain: %w", err)
+		}
+		defer close()
+		onChain, err := readOnChainApplication(ctx, contracts, address, consensusAddress)
+		if err != nil {
+			return err
+		}
+		if expectedTemplateHash != nil && *expectedTemplateHash != onChain.TemplateHash {
+			return fmt.Errorf("template hashes do not match. Deployed: %s. Configured: %s",
+				onChain.TemplateHash, expectedTemplateHash)
+		}
+
+		app := &Application{
+			ContractAddress:    address,
+			TemplateHash:       onChain.TemplateHash,
+			TemplateUri:        templateUri,
+			LastProcessedBlock: lastProcessedBlock,
+			Status:             ApplicationStatusRunning,
+			IConsensusAddress:  consensusAddress,
+		}
+		if disabled {
+			app.Status = ApplicationStatusNotRunning
+		}
+		app.Id, err = repo.InsertApplication(ctx, app)
+		if err != nil {
+			return err
+		}
+
+		view := newApplicationView(app)
+		view.EpochLength = onChain.EpochLength
+		return printApplication(cmd.OutOrStdout(), view)
+	})
+}
+
+type applicationContract interface {
+	GetConsensus(opts *bind.CallOpts) (common.Address, error)
+	GetTemplateHash(opts *bind.CallOpts) ([32]byte, error)
+}
+
+type consensusContract interface {
+	GetEpochLength(opts *bind.CallOpts) (*big.Int, error)
+}
+
+type contractFactory interface {
+	NewApplication(address Address) (applicationContract, error)
+	NewConsensus(address Address) (consensusContract, error)
+}
+
+type ethContractFactory struct {
+	client *ethclient.Client
+}
+
+func (f ethContractFactory) NewApplication(address Address) (applicationContract, error) {
+	return iapplication.NewIApplicationCaller(address, f.client)
+}
+
+func (f ethContractFactory) NewConsensus(address Address) (consensusContract, error) {
+	return iconsensus.NewIConsensusCaller(address, f.client)
+}
+
+// dialContracts connects to the blockchain. Tests replace it with a fake.
+var dialContracts = func(ctx context.Context, endpoint string) (contractFactory, func(), error) {
+	client, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ethContractFactory{client}, client.Close, nil
+}
+
+// onChainApplication is what the contracts of an application say about it
+type onChainApplication struct {
+	TemplateHash Hash
+	EpochLength  uint64
+}
+
+// readOnChainApplication reads the template hash and the epoch length of the application,
+// failing if it does not use the expected consensus
+func readOnChainApplication(
+	ctx context.Context,
+	contracts contractFactory,
+	address Address,
+	expectedConsensus Address,
+) (*onChainApplication, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	application, err := contracts.NewApplication(address)
+	if err != nil {
+		return nil, fmt.Errorf("error building application contract: %w", err)
+	}
+	consensusAddress, err := application.GetConsensus(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving application consensus: %w", err)
+	}
+	if consensusAddress != expectedConsensus {
+		return nil, fmt.Errorf("IConsensus addresses do not match. Deployed: %s. Configured: %s",
+			consensusAddress, expectedConsensus)
+	}
+	templateHash, err := application.GetTemplateHash(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving application template hash: %w", err)
+	}
+
+	consensusCaller, err := contracts.NewConsensus(consensusAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error building consensus contract: %w", err)
+	}
+	epochLength, err := consensusCaller.GetEpochLength(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving epoch length: %w", err)
+	}
+	if !epochLength.IsUint64() || epochLength.Sign() == 0 {
+		return nil, fmt.Errorf("invalid epoch length %s", epochLength)
+	}
+
+	return &onChainApplication{
+		TemplateHash: templateHash,
+		EpochLength:  epochLength.Uint64(),
+	}, nil
+}
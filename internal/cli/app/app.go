@@ -0,0 +1,90 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package app implements the commands that manage the applications read by the node.
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "app",
+	Short: "Manages the applications read by the node",
+	Long: `Manages the applications read by the node.
+
+The database is the one set by --postgres-endpoint, or by CARTESI_POSTGRES_ENDPOINT.`,
+}
+
+var (
+	postgresEndpoint string
+	output           string
+)
+
+func init() {
+	Cmd.PersistentFlags().StringVar(&postgresEndpoint, "postgres-endpoint", "",
+		"Postgres endpoint (default: CARTESI_POSTGRES_ENDPOINT)")
+	Cmd.PersistentFlags().StringVarP(&output, "output", "o", cliutil.OutputTable, "Output format, table or json")
+
+	Cmd.AddCommand(addCmd, listCmd, showCmd, enableCmd, disableCmd, removeCmd)
+}
+
+// applicationRepository is the part of the repository the app commands use
+type applicationRepository interface {
+	GetApplication(ctx context.Context, appAddressKey Address) (*Application, error)
+	GetAllApplications(ctx context.Context) ([]Application, error)
+	InsertApplication(ctx context.Context, app *Application) (uint64, error)
+	UpdateApplicationStatus(ctx context.Context, appAddressKey Address, newStatus ApplicationStatus) error
+	DeleteApplication(ctx context.Context, appAddressKey Address) error
+}
+
+// openRepository connects to the database. Tests replace it with a fake.
+var openRepository = func(ctx context.Context) (applicationRepository, func(), error) {
+	database, err := cliutil.ConnectDatabase(ctx, postgresEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return database, database.Close, nil
+}
+
+// withRepository validates the output format and runs f with a database connection
+func withRepository(
+	cmd *cobra.Command,
+	f func(ctx context.Context, repo applicationRepository) error,
+) error {
+	if err := cliutil.ValidateOutput(output); err != nil {
+		return err
+	}
+	repo, close, err := openRepository(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer close()
+	return f(cmd.Context(), repo)
+}
+
+func parseAddress(name string, s string) (Address, error) {
+	if !common.IsHexAddress(s) {
+		return Address{}, fmt.Errorf("%s must be an address, got '%s'", name, s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// getApplication fails if the application is not registered
+func getApplication(ctx context.Context, repo applicationRepository, address Address) (*Application, error) {
+	app, err := repo.GetApplication(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, fmt.Errorf("application %s is not registered", address)
+	}
+	return app, nil
+}
@@ -0,0 +1,283 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/repository"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	testApp          = common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+	testConsensus    = common.HexToAddress("0xdeadbeef")
+	testTemplateHash = common.HexToHash("0xfeedbeef")
+)
+
+type AppSuite struct {
+	suite.Suite
+	repository *fakeRepository
+	contracts  *fakeContracts
+}
+
+func TestAppSuite(t *testing.T) {
+	suite.Run(t, new(AppSuite))
+}
+
+func (s *AppSuite) SetupTest() {
+	s.repository = &fakeRepository{apps: map[Address]*Application{}}
+	s.contracts = &fakeContracts{
+		consensus:    testConsensus,
+		templateHash: testTemplateHash,
+		epochLength:  big.NewInt(10),
+	}
+	openRepository = func(ctx context.Context) (applicationRepository, func(), error) {
+		return s.repository, func() {}, nil
+	}
+	dialContracts = func(ctx context.Context, endpoint string) (contractFactory, func(), error) {
+		return s.contracts, func() {}, nil
+	}
+}
+
+func (s *AppSuite) TestAddReadsTheApplicationFromTheChain() {
+	out, err := s.execute("add", testApp.Hex(), "--consensus", testConsensus.Hex(),
+		"--template-uri", "/snapshots/app", "--blockchain-http-endpoint", "http://localhost:8545",
+		"-o", "json")
+	s.Require().Nil(err)
+
+	var view applicationView
+	s.Require().Nil(json.Unmarshal(out, &view))
+	s.Equal(testApp, view.Address)
+	s.Equal(testTemplateHash, view.TemplateHash)
+	s.Equal(uint64(10), view.EpochLength)
+	s.Equal(ApplicationStatusRunning, view.Status)
+
+	app := s.repository.apps[testApp]
+	s.Require().NotNil(app)
+	s.Equal(testConsensus, app.IConsensusAddress)
+	s.Equal(testTemplateHash, app.TemplateHash)
+	s.Equal("/snapshots/app", app.TemplateUri)
+}
+
+func (s *AppSuite) TestAddReadsTheEndpointFromASecretFile() {
+	file := filepath.Join(s.T().TempDir(), "endpoint")
+	s.Require().Nil(os.WriteFile(file, []byte("http://secret:8545\n"), 0600))
+	s.T().Setenv("CARTESI_BLOCKCHAIN_HTTP_ENDPOINT_FILE", file)
+	var dialed string
+	dialContracts = func(ctx context.Context, endpoint string) (contractFactory, func(), error) {
+		dialed = endpoint
+		return s.contracts, func() {}, nil
+	}
+
+	_, err := s.execute("add", testApp.Hex(), "--consensus", testConsensus.Hex())
+	s.Require().Nil(err)
+	s.Equal("http://secret:8545", dialed)
+}
+
+func (s *AppSuite) TestAddFailsWhenTheChainDisagrees() {
+	args := []string{"add", testApp.Hex(), "--blockchain-http-endpoint", "http://localhost:8545"}
+
+	_, err := s.execute(append(args, "--consensus", common.HexToAddress("0xfeedbeef").Hex())...)
+	s.ErrorContains(err, "IConsensus addresses do not match")
+
+	_, err = s.execute(append(args, "--consensus", testConsensus.Hex(),
+		"--template-hash", common.HexToHash("0x1234").Hex())...)
+	s.ErrorContains(err, "template hashes do not match")
+
+	s.contracts.epochLength = big.NewInt(0)
+	_, err = s.execute(append(args, "--consensus", testConsensus.Hex())...)
+	s.ErrorContains(err, "invalid epoch length")
+
+	s.contracts.epochLength = big.NewInt(10)
+	s.contracts.err = errors.New("no contract code at given address")
+	_, err = s.execute(append(args, "--consensus", testConsensus.Hex())...)
+	s.ErrorContains(err, "no contract code")
+
+	s.Empty(s.repository.apps)
+}
+
+func (s *AppSuite) TestAddFailsForRegisteredApplications() {
+	s.repository.apps[testApp] = &Application{ContractAddress: testApp}
+	_, err := s.execute("add", testApp.Hex(), "--consensus", testConsensus.Hex(),
+		"--blockchain-http-endpoint", "http://localhost:8545")
+	s.ErrorContains(err, "already registered")
+}
+
+func (s *AppSuite) TestListAndShow() {
+	s.repository.apps[testApp] = &Application{
+		ContractAddress:    testApp,
+		IConsensusAddress:  testConsensus,
+		TemplateHash:       testTemplateHash,
+		LastProcessedBlock: 42,
+		Status:             ApplicationStatusRunning,
+	}
+
+	out, err := s.execute("list")
+	s.Require().Nil(err)
+	s.Contains(string(out), "LAST PROCESSED BLOCK")
+	s.Contains(string(out), testApp.Hex())
+
+	out, err = s.execute("list", "-o", "json")
+	s.Require().Nil(err)
+	var views []applicationView
+	s.Require().Nil(json.Unmarshal(out, &views))
+	s.Require().Len(views, 1)
+	s.Equal(uint64(42), views[0].LastProcessedBlock)
+
+	out, err = s.execute("show", testApp.Hex())
+	s.Require().Nil(err)
+	s.Contains(string(out), "Last processed block:     42")
+
+	_, err = s.execute("show", testConsensus.Hex())
+	s.ErrorContains(err, "not registered")
+
+	_, err = s.execute("list", "-o", "yaml")
+	s.ErrorContains(err, "--output must be")
+}
+
+func (s *AppSuite) TestEnableAndDisable() {
+	s.repository.apps[testApp] = &Application{
+		ContractAddress: testApp,
+		Status:          ApplicationStatusRunning,
+	}
+
+	_, err := s.execute("disable", testApp.Hex())
+	s.Require().Nil(err)
+	s.Equal(ApplicationStatusNotRunning, s.repository.apps[testApp].Status)
+
+	_, err = s.execute("enable", testApp.Hex())
+	s.Require().Nil(err)
+	s.Equal(ApplicationStatusRunning, s.repository.apps[testApp].Status)
+
+	_, err = s.execute("enable", testConsensus.Hex())
+	s.ErrorContains(err, "not registered")
+}
+
+func (s *AppSuite) TestRemove() {
+	s.repository.apps[testApp] = &Application{ContractAddress: testApp}
+
+	_, err := s.execute("remove", testApp.Hex())
+	s.Require().Nil(err)
+	s.Empty(s.repository.apps)
+
+	_, err = s.execute("remove", testApp.Hex())
+	s.ErrorContains(err, "not registered")
+
+	s.repository.apps[testApp] = &Application{ContractAddress: testApp}
+	s.repository.inUse = true
+	_, err = s.execute("remove", testApp.Hex())
+	s.ErrorContains(err, "disable it instead")
+}
+
+func (s *AppSuite) execute(args ...string) ([]byte, error) {
+	var out bytes.Buffer
+	Cmd.SetArgs(args)
+	Cmd.SetOut(&out)
+	Cmd.SetErr(&bytes.Buffer{})
+	_, err := Cmd.ExecuteC()
+
+	// flags keep their values between executions
+	for _, cmd := range append(Cmd.Commands(), Cmd) {
+		resetFlags(cmd)
+	}
+	return out.Bytes(), err
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+}
+
+type fakeRepository struct {
+	apps  map[Address]*Application
+	inUse bool
+}
+
+func (r *fakeRepository) GetApplication(ctx context.Context, address Address) (*Application, error) {
+	app, ok := r.apps[address]
+	if !ok {
+		return nil, nil
+	}
+	copy := *app
+	return &copy, nil
+}
+
+func (r *fakeRepository) GetAllApplications(ctx context.Context) ([]Application, error) {
+	apps := []Application{}
+	for _, app := range r.apps {
+		apps = append(apps, *app)
+	}
+	return apps, nil
+}
+
+func (r *fakeRepository) InsertApplication(ctx context.Context, app *Application) (uint64, error) {
+	copy := *app
+	r.apps[app.ContractAddress] = &copy
+	return uint64(len(r.apps)), nil
+}
+
+func (r *fakeRepository) UpdateApplicationStatus(
+	ctx context.Context,
+	address Address,
+	status ApplicationStatus,
+) error {
+	r.apps[address].Status = status
+	return nil
+}
+
+func (r *fakeRepository) DeleteApplication(ctx context.Context, address Address) error {
+	if _, ok := r.apps[address]; !ok {
+		return repository.ErrApplicationNotFound
+	}
+	if r.inUse {
+		return repository.ErrApplicationInUse
+	}
+	delete(r.apps, address)
+	return nil
+}
+
+type fakeContracts struct {
+	consensus    Address
+	templateHash Hash
+	epochLength  *big.Int
+	err          error
+}
+
+func (c *fakeContracts) NewApplication(address Address) (applicationContract, error) {
+	return c, nil
+}
+
+func (c *fakeContracts) NewConsensus(address Address) (consensusContract, error) {
+	return c, nil
+}
+
+func (c *fakeContracts) GetConsensus(opts *bind.CallOpts) (common.Address, error) {
+	return c.consensus, c.err
+}
+
+func (c *fakeContracts) GetTemplateHash(opts *bind.CallOpts) ([32]byte, error) {
+	return c.templateHash, c.err
+}
+
+func (c *fakeContracts) GetEpochLength(opts *bind.CallOpts) (*big.Int, error) {
+	return c.epochLength, c.err
+}
@@ -0,0 +1,123 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/repository"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the registered applications",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withRepository(cmd, func(ctx context.Context, repo applicationRepository) error {
+			apps, err := repo.GetAllApplications(ctx)
+			if err != nil {
+				return err
+			}
+			views := make([]applicationView, 0, len(apps))
+			for i := range apps {
+				views = append(views, newApplicationView(&apps[i]))
+			}
+			return printApplications(cmd.OutOrStdout(), views)
+		})
+	},
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <address>",
+	Short: "Shows a registered application",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, err := parseAddress("application", args[0])
+		if err != nil {
+			return err
+		}
+		return withRepository(cmd, func(ctx context.Context, repo applicationRepository) error {
+			app, err := getApplication(ctx, repo, address)
+			if err != nil {
+				return err
+			}
+			return printApplication(cmd.OutOrStdout(), newApplicationView(app))
+		})
+	},
+}
+
+var enableCmd = &cobra.Command{
+	Use:   "enable <address>",
+	Short: "Enables the reading of an application",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setStatus(cmd, args[0], ApplicationStatusRunning)
+	},
+}
+
+var disableCmd = &cobra.Command{
+	Use:   "disable <address>",
+	Short: "Disables the reading of an application",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setStatus(cmd, args[0], ApplicationStatusNotRunning)
+	},
+}
+
+func setStatus(cmd *cobra.Command, arg string, status ApplicationStatus) error {
+	address, err := parseAddress("application", arg)
+	if err != nil {
+		return err
+	}
+	return withRepository(cmd, func(ctx context.Context, repo applicationRepository) error {
+		if _, err := getApplication(ctx, repo, address); err != nil {
+			return err
+		}
+		if err := repo.UpdateApplicationStatus(ctx, address, status); err != nil {
+			return err
+		}
+		app, err := getApplication(ctx, repo, address)
+		if err != nil {
+			return err
+		}
+		return printApplication(cmd.OutOrStdout(), newApplicationView(app))
+	})
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove <address>",
+	Short: "Removes an application that has no inputs yet",
+	Long: `Removes an application that has no inputs yet.
+
+Applications with epochs or inputs can't be removed, disable them instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, err := parseAddress("application", args[0])
+		if err != nil {
+			return err
+		}
+		return withRepository(cmd, func(ctx context.Context, repo applicationRepository) error {
+			err := repo.DeleteApplication(ctx, address)
+			switch {
+			case errors.Is(err, repository.ErrApplicationNotFound):
+				return fmt.Errorf("application %s is not registered", address)
+			case errors.Is(err, repository.ErrApplicationInUse):
+				return fmt.Errorf("application %s has epochs or inputs, disable it instead", address)
+			case err != nil:
+				return err
+			}
+			if output == cliutil.OutputJSON {
+				return cliutil.PrintJSON(cmd.OutOrStdout(), map[string]Address{"removed": address})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "removed application %s\n", address)
+			return nil
+		})
+	},
+}
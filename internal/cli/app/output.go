@@ -0,0 +1,74 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+)
+
+// applicationView is how applications are printed
+type applicationView struct {
+	Address              Address           `json:"address"`
+	Consensus            Address           `json:"consensus"`
+	TemplateHash         Hash              `json:"template_hash"`
+	TemplateUri          string            `json:"template_uri"`
+	Status               ApplicationStatus `json:"status"`
+	LastProcessedBlock   uint64            `json:"last_processed_block"`
+	LastClaimCheckBlock  uint64            `json:"last_claim_check_block"`
+	LastOutputCheckBlock uint64            `json:"last_output_check_block"`
+	// Only known when the application was read from the chain
+	EpochLength uint64 `json:"epoch_length,omitempty"`
+}
+
+func newApplicationView(app *Application) applicationView {
+	return applicationView{
+		Address:              app.ContractAddress,
+		Consensus:            app.IConsensusAddress,
+		TemplateHash:         app.TemplateHash,
+		TemplateUri:          app.TemplateUri,
+		Status:               app.Status,
+		LastProcessedBlock:   app.LastProcessedBlock,
+		LastClaimCheckBlock:  app.LastClaimCheckBlock,
+		LastOutputCheckBlock: app.LastOutputCheckBlock,
+	}
+}
+
+// printApplications prints one application per row
+func printApplications(w io.Writer, apps []applicationView) error {
+	if output == cliutil.OutputJSON {
+		return cliutil.PrintJSON(w, apps)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tCONSENSUS\tSTATUS\tLAST PROCESSED BLOCK\tTEMPLATE HASH")
+	for _, app := range apps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n",
+			app.Address, app.Consensus, app.Status, app.LastProcessedBlock, app.TemplateHash)
+	}
+	return tw.Flush()
+}
+
+// printApplication prints every field of an application, one per line
+func printApplication(w io.Writer, app applicationView) error {
+	if output == cliutil.OutputJSON {
+		return cliutil.PrintJSON(w, app)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Address:\t%s\n", app.Address)
+	fmt.Fprintf(tw, "Consensus:\t%s\n", app.Consensus)
+	fmt.Fprintf(tw, "Template hash:\t%s\n", app.TemplateHash)
+	fmt.Fprintf(tw, "Template URI:\t%s\n", app.TemplateUri)
+	fmt.Fprintf(tw, "Status:\t%s\n", app.Status)
+	fmt.Fprintf(tw, "Last processed block:\t%d\n", app.LastProcessedBlock)
+	fmt.Fprintf(tw, "Last claim check block:\t%d\n", app.LastClaimCheckBlock)
+	fmt.Fprintf(tw, "Last output check block:\t%d\n", app.LastOutputCheckBlock)
+	if app.EpochLength != 0 {
+		fmt.Fprintf(tw, "Epoch length:\t%d\n", app.EpochLength)
+	}
+	return tw.Flush()
+}
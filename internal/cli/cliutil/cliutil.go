@@ -0,0 +1,91 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package cliutil holds what the commands of the node have in common.
+package cliutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+	"github.com/ZzzzHui/espresso-reader/internal/repository"
+)
+
+// Output formats of the commands that print records
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// PostgresEndpoint returns the endpoint set by the --postgres-endpoint flag,
+// or by CARTESI_POSTGRES_ENDPOINT
+func PostgresEndpoint(flag string) (string, error) {
+	endpoint := flag
+	if endpoint == "" {
+		endpoint = config.GetPostgresEndpoint()
+	}
+	if endpoint == "" {
+		return "", fmt.Errorf("--postgres-endpoint or CARTESI_POSTGRES_ENDPOINT must be set")
+	}
+	return endpoint, nil
+}
+
+// BlockchainHttpEndpoint returns the endpoint set by the --blockchain-http-endpoint flag,
+// or by CARTESI_BLOCKCHAIN_HTTP_ENDPOINT and its _FILE variant
+func BlockchainHttpEndpoint(flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	endpoint, _, err := config.Lookup("CARTESI_BLOCKCHAIN_HTTP_ENDPOINT")
+	if err != nil && !errors.Is(err, config.ErrUnset) {
+		return "", err
+	}
+	if endpoint == "" {
+		return "", fmt.Errorf(
+			"--blockchain-http-endpoint or CARTESI_BLOCKCHAIN_HTTP_ENDPOINT must be set")
+	}
+	return endpoint, nil
+}
+
+// ServiceUrl returns the URL set by the --service-url flag, or the one of ESPRESSO_SERVICE_ENDPOINT.
+// The variable is read from the environment when the command runs, as these commands take no
+// config file. The URL is HTTPS when the ESPRESSO_SERVICE_TLS_* files are set, as the service is.
+func ServiceUrl(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	scheme := "http://"
+	if config.GetServiceTlsCertFile() != "" || config.GetServiceTlsKeyFile() != "" {
+		scheme = "https://"
+	}
+	return scheme + config.GetServiceEndpoint()
+}
+
+// ConnectDatabase connects to the database set by the --postgres-endpoint flag,
+// or by CARTESI_POSTGRES_ENDPOINT
+func ConnectDatabase(ctx context.Context, flag string) (*repository.Database, error) {
+	endpoint, err := PostgresEndpoint(flag)
+	if err != nil {
+		return nil, err
+	}
+	return repository.Connect(ctx, endpoint)
+}
+
+// ValidateOutput fails unless output is one of the output formats
+func ValidateOutput(output string) error {
+	if output != OutputTable && output != OutputJSON {
+		return fmt.Errorf("--output must be %s or %s, got '%s'", OutputTable, OutputJSON, output)
+	}
+	return nil
+}
+
+// PrintJSON prints v as indented JSON
+func PrintJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
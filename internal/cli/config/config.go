@@ -0,0 +1,143 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package config implements the commands that inspect the configuration of the node.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	nodeconfig "github.com/ZzzzHui/espresso-reader/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+const redacted = "[REDACTED]"
+
+var Cmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspects the configuration of the node",
+}
+
+var printCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Prints the effective configuration",
+	Long: `Prints the effective configuration of the node.
+
+Each variable is read from its flag, its environment variable or the config file,
+in this order of precedence, falling back to its default.
+The source of each value is printed alongside it, and secrets are redacted.`,
+	Example: `espresso-reader config print
+espresso-reader config print --config espresso-reader.toml --log-level debug -o json`,
+	Args: cobra.NoArgs,
+	RunE: runPrint,
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks the configuration without starting the node",
+	Long: `Checks the configuration without starting the node.
+
+Reports all the missing and malformed variables at once, then all the invalid values:
+URLs, addresses, the variables each auth kind requires and numeric ranges.`,
+	Example: `espresso-reader config check
+espresso-reader config check --config espresso-reader.yaml`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runCheck,
+}
+
+var (
+	configFile string
+	output     string
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{printCmd, checkCmd} {
+		cmd.Flags().StringVar(&configFile, "config", "", "Path to a TOML or YAML config file")
+		nodeconfig.AddFlags(cmd.Flags())
+	}
+	printCmd.Flags().StringVarP(&output, "output", "o", cliutil.OutputTable, "Output format, table or json")
+
+	Cmd.AddCommand(printCmd, checkCmd)
+}
+
+func loadFile() error {
+	if configFile == "" {
+		return nil
+	}
+	return nodeconfig.LoadFile(configFile)
+}
+
+type variable struct {
+	Name   string            `json:"name"`
+	Value  *string           `json:"value"`
+	Source nodeconfig.Source `json:"source,omitempty"`
+}
+
+func runPrint(cmd *cobra.Command, args []string) error {
+	if err := cliutil.ValidateOutput(output); err != nil {
+		return err
+	}
+	if err := loadFile(); err != nil {
+		return err
+	}
+
+	variables := make([]variable, len(nodeconfig.Variables))
+	for i, v := range nodeconfig.Variables {
+		variables[i].Name = v.Name
+		value, source, err := nodeconfig.Lookup(v.Name)
+		if errors.Is(err, nodeconfig.ErrUnset) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if v.Redact && value != "" {
+			value = redacted
+		}
+		variables[i].Value = &value
+		variables[i].Source = source
+	}
+
+	if output == cliutil.OutputJSON {
+		return cliutil.PrintJSON(cmd.OutOrStdout(), variables)
+	}
+	return printTable(cmd.OutOrStdout(), variables)
+}
+
+func printTable(out io.Writer, variables []variable) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VARIABLE\tVALUE\tSOURCE")
+	for _, v := range variables {
+		if v.Value == nil {
+			fmt.Fprintf(w, "%s\t-\tunset\n", v.Name)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%q\t%s\n", v.Name, *v.Value, v.Source)
+	}
+	return w.Flush()
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	if err := loadFile(); err != nil {
+		return err
+	}
+	_, err := nodeconfig.Load()
+	var invalid *nodeconfig.ValidationError
+	if errors.As(err, &invalid) {
+		for _, problem := range invalid.Problems {
+			fmt.Fprintln(cmd.OutOrStdout(), problem)
+		}
+		return fmt.Errorf("found %d problems in the config", len(invalid.Problems))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "config is valid")
+	return nil
+}
@@ -0,0 +1,141 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package db implements the commands that manage the database of the node.
+package db
+
+import (
+	"fmt"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	"github.com/ZzzzHui/espresso-reader/internal/repository/schema"
+
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manages the database of the node",
+	Long: `Manages the database of the node.
+
+The database is the one set by --postgres-endpoint, or by CARTESI_POSTGRES_ENDPOINT.`,
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manages the migrations of the database schema",
+}
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applies the pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSchema(func(s *schema.Schema) error {
+			if err := s.Upgrade(); err != nil {
+				return err
+			}
+			return printVersion(cmd, s)
+		})
+	},
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Reverts the last migrations",
+	Long: `Reverts the last migrations.
+
+Reverting migrations drops the tables they created, along with their data.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSchema(func(s *schema.Schema) error {
+			if all {
+				if err := s.Downgrade(); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "reverted all migrations")
+				return nil
+			}
+			if err := s.DowngradeSteps(steps); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "reverted %d migrations\n", steps)
+			return nil
+		})
+	},
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Shows the version of the database schema",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSchema(func(s *schema.Schema) error {
+			return printVersion(cmd, s)
+		})
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Fails if the database schema is not at the version the node expects",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withSchema(func(s *schema.Schema) error {
+			version, err := s.ValidateVersion()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "database schema is at the expected version %d\n", version)
+			return nil
+		})
+	},
+}
+
+var (
+	postgresEndpoint string
+	steps            uint
+	all              bool
+)
+
+func init() {
+	Cmd.PersistentFlags().StringVar(&postgresEndpoint, "postgres-endpoint", "",
+		"Postgres endpoint (default: CARTESI_POSTGRES_ENDPOINT)")
+
+	downCmd.Flags().UintVar(&steps, "steps", 1, "Number of migrations to revert")
+	downCmd.Flags().BoolVar(&all, "all", false, "Revert all migrations")
+	downCmd.MarkFlagsMutuallyExclusive("steps", "all")
+
+	migrateCmd.AddCommand(upCmd, downCmd, versionCmd, validateCmd)
+	Cmd.AddCommand(migrateCmd)
+}
+
+func withSchema(f func(s *schema.Schema) error) error {
+	endpoint, err := cliutil.PostgresEndpoint(postgresEndpoint)
+	if err != nil {
+		return err
+	}
+	s, err := schema.New(endpoint)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return f(s)
+}
+
+func printVersion(cmd *cobra.Command, s *schema.Schema) error {
+	version, err := s.Version()
+	if err != nil {
+		return err
+	}
+	dirty, err := s.Dirty()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "version: %d (expected: %d)", version, schema.ExpectedVersion)
+	if dirty {
+		fmt.Fprint(cmd.OutOrStdout(), " dirty")
+	}
+	fmt.Fprintln(cmd.OutOrStdout())
+	return nil
+}
@@ -0,0 +1,60 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package db
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/suite"
+)
+
+type DbSuite struct {
+	suite.Suite
+}
+
+func TestDbSuite(t *testing.T) {
+	suite.Run(t, new(DbSuite))
+}
+
+func (s *DbSuite) TestItRequiresAnEndpoint() {
+	s.T().Setenv("CARTESI_POSTGRES_ENDPOINT", "")
+	for _, args := range [][]string{
+		{"migrate", "up"},
+		{"migrate", "down"},
+		{"migrate", "version"},
+		{"migrate", "validate"},
+		{"node-config", "show"},
+	} {
+		s.ErrorContains(s.execute(args...), "CARTESI_POSTGRES_ENDPOINT must be set", args)
+	}
+}
+
+func (s *DbSuite) TestDownTakesEitherStepsOrAll() {
+	s.ErrorContains(s.execute("migrate", "down", "--steps", "2", "--all"), "none of the others can be")
+}
+
+func (s *DbSuite) TestUpdateNodeConfigRequiresAValidConfig() {
+	s.T().Setenv("CARTESI_BLOCKCHAIN_ID", "chain")
+	s.ErrorContains(s.execute("node-config", "update"), "invalid config: ")
+	s.ErrorContains(s.execute("node-config", "update"), "CARTESI_BLOCKCHAIN_ID: strconv.ParseUint")
+}
+
+func (s *DbSuite) execute(args ...string) error {
+	Cmd.SetArgs(args)
+	Cmd.SetOut(&bytes.Buffer{})
+	Cmd.SetErr(&bytes.Buffer{})
+	err := Cmd.Execute()
+
+	// flags keep their values between executions
+	reset := func(flag *pflag.Flag) {
+		flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	}
+	Cmd.PersistentFlags().VisitAll(reset)
+	downCmd.Flags().VisitAll(reset)
+	nodeConfigUpdateCmd.Flags().VisitAll(reset)
+	return err
+}
@@ -0,0 +1,137 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/repository"
+	"github.com/ZzzzHui/espresso-reader/internal/services/startup"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+)
+
+var nodeConfigCmd = &cobra.Command{
+	Use:   "node-config",
+	Short: "Manages the config the node persisted in the database",
+	Long: `Manages the config the node persisted in the database.
+
+The node persists the chain id, the InputBox address and its deployment block
+the first time it runs, and refuses to start when they disagree with its config.`,
+}
+
+var nodeConfigShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the persisted config",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDatabase(cmd, func(ctx context.Context, database *repository.Database) error {
+			persisted, err := getNodeConfig(ctx, database)
+			if err != nil {
+				return err
+			}
+			if persisted == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "no config persisted yet")
+				return nil
+			}
+			return printNodeConfig(cmd.OutOrStdout(), persisted)
+		})
+	},
+}
+
+var nodeConfigUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Replaces the persisted config with the configured one",
+	Long: `Replaces the persisted config with the one set by the environment and the config file.
+
+Use it when the node is moved on purpose to another chain or InputBox deployment.
+The inputs already read from the previous one stay in the database.`,
+	Example: `espresso-reader db node-config update
+espresso-reader db node-config update --config espresso-reader.toml`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFile != "" {
+			if err := config.LoadFile(configFile); err != nil {
+				return err
+			}
+		}
+		c, err := config.Load()
+		if err != nil {
+			return err
+		}
+		configured := startup.NewNodePersistentConfig(c)
+
+		return withDatabase(cmd, func(ctx context.Context, database *repository.Database) error {
+			persisted, err := getNodeConfig(ctx, database)
+			if err != nil {
+				return err
+			}
+			if persisted != nil && *persisted == *configured {
+				fmt.Fprintln(cmd.OutOrStdout(), "the persisted config is already the configured one")
+				return nil
+			}
+			if persisted != nil {
+				var mismatch *startup.PreflightError
+				if errors.As(startup.CheckNodePersistentConfig(persisted, configured), &mismatch) {
+					for _, problem := range mismatch.Problems {
+						fmt.Fprintln(cmd.OutOrStdout(), problem)
+					}
+				}
+			}
+			if err := database.UpdateNodeConfig(ctx, configured); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "persisted the configured config:")
+			return printNodeConfig(cmd.OutOrStdout(), configured)
+		})
+	},
+}
+
+var configFile string
+
+func init() {
+	nodeConfigUpdateCmd.Flags().StringVar(&configFile, "config", "", "Path to a TOML or YAML config file")
+
+	nodeConfigCmd.AddCommand(nodeConfigShowCmd, nodeConfigUpdateCmd)
+	Cmd.AddCommand(nodeConfigCmd)
+}
+
+func withDatabase(
+	cmd *cobra.Command,
+	f func(ctx context.Context, database *repository.Database) error,
+) error {
+	database, err := cliutil.ConnectDatabase(cmd.Context(), postgresEndpoint)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	return f(cmd.Context(), database)
+}
+
+// getNodeConfig returns the persisted config, or nil if there is none yet
+func getNodeConfig(ctx context.Context, database *repository.Database) (*model.NodePersistentConfig, error) {
+	persisted, err := database.GetNodeConfig(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return persisted, err
+}
+
+func printNodeConfig(w io.Writer, c *model.NodePersistentConfig) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Chain id:\t%d\n", c.ChainId)
+	fmt.Fprintf(tw, "InputBox address:\t%s\n", c.InputBoxAddress)
+	fmt.Fprintf(tw, "InputBox deployment block:\t%d\n", c.InputBoxDeploymentBlock)
+	fmt.Fprintf(tw, "Default block:\t%s\n", c.DefaultBlock)
+	return tw.Flush()
+}
@@ -0,0 +1,209 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package input implements the commands that inspect the inputs read by the node.
+package input
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/decoder"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "input",
+	Short: "Inspects the inputs read by the node",
+	Long: `Inspects the inputs read by the node.
+
+The database is the one set by --postgres-endpoint, or by CARTESI_POSTGRES_ENDPOINT.`,
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows an input with its decoded metadata and payload",
+	Example: `espresso-reader input show --app 0x2E663fe9aE92275242406A185AA4fC8174339D3E --index 0
+espresso-reader input show --app 0x2E663fe9aE92275242406A185AA4fC8174339D3E --index 0 -o json | jq .decoded.payload`,
+	Args: cobra.NoArgs,
+	RunE: runShow,
+}
+
+var (
+	postgresEndpoint string
+	output           string
+	app              string
+	index            uint64
+)
+
+// Where an input comes from
+const (
+	sourceEspresso = "espresso"
+	sourceInputBox = "inputbox"
+)
+
+func init() {
+	Cmd.PersistentFlags().StringVar(&postgresEndpoint, "postgres-endpoint", "",
+		"Postgres endpoint (default: CARTESI_POSTGRES_ENDPOINT)")
+	Cmd.PersistentFlags().StringVarP(&output, "output", "o", cliutil.OutputTable, "Output format, table or json")
+
+	showCmd.Flags().StringVar(&app, "app", "", "Application address")
+	showCmd.Flags().Uint64Var(&index, "index", 0, "Input index")
+	cobra.CheckErr(showCmd.MarkFlagRequired("app"))
+	cobra.CheckErr(showCmd.MarkFlagRequired("index"))
+
+	Cmd.AddCommand(showCmd)
+}
+
+// inputRepository is the part of the repository the input commands use
+type inputRepository interface {
+	GetInput(ctx context.Context, appAddressKey Address, indexKey uint64) (*Input, error)
+	GetEpochs(ctx context.Context, application Address) ([]Epoch, error)
+}
+
+// openRepository connects to the database. Tests replace it with a fake.
+var openRepository = func(ctx context.Context) (inputRepository, func(), error) {
+	database, err := cliutil.ConnectDatabase(ctx, postgresEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return database, database.Close, nil
+}
+
+type epochView struct {
+	Index      uint64      `json:"index"`
+	FirstBlock uint64      `json:"first_block"`
+	LastBlock  uint64      `json:"last_block"`
+	Status     EpochStatus `json:"status"`
+}
+
+// inputView is how inputs are printed
+type inputView struct {
+	App         Address               `json:"app"`
+	Index       uint64                `json:"index"`
+	Status      InputCompletionStatus `json:"status"`
+	BlockNumber uint64                `json:"block_number"`
+	Epoch       *epochView            `json:"epoch"`
+	Source      string                `json:"source"`
+	// Set for inputs sequenced by Espresso
+	TransactionId hexutil.Bytes `json:"transaction_id,omitempty"`
+	MachineHash   *Hash         `json:"machine_hash,omitempty"`
+	OutputsHash   *Hash         `json:"outputs_hash,omitempty"`
+	RawData       hexutil.Bytes `json:"raw_data"`
+	// Nil when the raw data can't be decoded, see DecodeError
+	Decoded     *decoder.EvmAdvance `json:"decoded"`
+	DecodeError string              `json:"decode_error,omitempty"`
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	if !common.IsHexAddress(app) {
+		return fmt.Errorf("--app must be an address, got '%s'", app)
+	}
+	appAddress := common.HexToAddress(app)
+	if err := cliutil.ValidateOutput(output); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	repo, close, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	input, err := repo.GetInput(ctx, appAddress, index)
+	if err != nil {
+		return err
+	}
+	if input == nil {
+		return fmt.Errorf("application %s has no input %d", appAddress, index)
+	}
+	epochs, err := repo.GetEpochs(ctx, appAddress)
+	if err != nil {
+		return err
+	}
+
+	view := newInputView(input, epochs)
+	if output == cliutil.OutputJSON {
+		return cliutil.PrintJSON(cmd.OutOrStdout(), view)
+	}
+	return printInput(cmd.OutOrStdout(), view)
+}
+
+func newInputView(input *Input, epochs []Epoch) inputView {
+	view := inputView{
+		App:           input.AppAddress,
+		Index:         input.Index,
+		Status:        input.CompletionStatus,
+		BlockNumber:   input.BlockNumber,
+		Source:        sourceInputBox,
+		TransactionId: hexutil.Bytes(input.TransactionId),
+		MachineHash:   input.MachineHash,
+		OutputsHash:   input.OutputsHash,
+		RawData:       hexutil.Bytes(input.RawData),
+	}
+	if len(input.TransactionId) > 0 {
+		view.Source = sourceEspresso
+	}
+	for _, epoch := range epochs {
+		if epoch.Id == input.EpochId {
+			view.Epoch = &epochView{
+				Index:      epoch.Index,
+				FirstBlock: epoch.FirstBlock,
+				LastBlock:  epoch.LastBlock,
+				Status:     epoch.Status,
+			}
+			break
+		}
+	}
+	decoded, err := decoder.DecodeEvmAdvance(input.RawData)
+	if err != nil {
+		view.DecodeError = err.Error()
+	} else {
+		view.Decoded = decoded
+	}
+	return view
+}
+
+func printInput(w io.Writer, input inputView) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Application:\t%s\n", input.App)
+	fmt.Fprintf(tw, "Index:\t%d\n", input.Index)
+	fmt.Fprintf(tw, "Status:\t%s\n", input.Status)
+	fmt.Fprintf(tw, "Block number:\t%d\n", input.BlockNumber)
+	if input.Epoch != nil {
+		fmt.Fprintf(tw, "Epoch:\t%d (blocks %d to %d, %s)\n",
+			input.Epoch.Index, input.Epoch.FirstBlock, input.Epoch.LastBlock, input.Epoch.Status)
+	}
+	fmt.Fprintf(tw, "Source:\t%s\n", input.Source)
+	if len(input.TransactionId) > 0 {
+		fmt.Fprintf(tw, "Transaction id:\t%s\n", input.TransactionId)
+	}
+	if input.MachineHash != nil {
+		fmt.Fprintf(tw, "Machine hash:\t%s\n", input.MachineHash)
+	}
+	if input.OutputsHash != nil {
+		fmt.Fprintf(tw, "Outputs hash:\t%s\n", input.OutputsHash)
+	}
+	if input.Decoded == nil {
+		fmt.Fprintf(tw, "Raw data:\t%s\n", input.RawData)
+		fmt.Fprintf(tw, "Decode error:\t%s\n", input.DecodeError)
+		return tw.Flush()
+	}
+	fmt.Fprintf(tw, "Chain id:\t%d\n", input.Decoded.ChainId)
+	fmt.Fprintf(tw, "App contract:\t%s\n", input.Decoded.AppContract)
+	fmt.Fprintf(tw, "Sender:\t%s\n", input.Decoded.MsgSender)
+	fmt.Fprintf(tw, "Block number (metadata):\t%d\n", input.Decoded.BlockNumber)
+	fmt.Fprintf(tw, "Block timestamp:\t%d\n", input.Decoded.BlockTimestamp)
+	fmt.Fprintf(tw, "Prev randao:\t%s\n", input.Decoded.PrevRandao)
+	fmt.Fprintf(tw, "Index (metadata):\t%d\n", input.Decoded.Index)
+	fmt.Fprintf(tw, "Payload:\t%s\n", input.Decoded.Payload)
+	return tw.Flush()
+}
@@ -0,0 +1,144 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/decoder"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/suite"
+)
+
+var testApp = common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+
+type InputSuite struct {
+	suite.Suite
+	repository *fakeRepository
+}
+
+func TestInputSuite(t *testing.T) {
+	suite.Run(t, new(InputSuite))
+}
+
+func (s *InputSuite) SetupTest() {
+	rawData, err := (&decoder.EvmAdvance{
+		ChainId:     11155111,
+		AppContract: testApp,
+		MsgSender:   common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		BlockNumber: 100,
+		PrevRandao:  (*hexutil.Big)(big.NewInt(42)),
+		Index:       1,
+		Payload:     common.FromHex("0xdeadbeef"),
+	}).Encode()
+	s.Require().Nil(err)
+
+	s.repository = &fakeRepository{
+		inputs: []*Input{
+			{Index: 0, AppAddress: testApp, EpochId: 1, RawData: common.FromHex("0x1234")},
+			{
+				Index:            1,
+				AppAddress:       testApp,
+				EpochId:          2,
+				RawData:          rawData,
+				BlockNumber:      100,
+				CompletionStatus: InputStatusAccepted,
+				TransactionId:    common.FromHex("0xfeedbeef"),
+			},
+		},
+		epochs: []Epoch{
+			{Id: 1, Index: 0, FirstBlock: 0, LastBlock: 9, Status: EpochStatusClaimAccepted},
+			{Id: 2, Index: 10, FirstBlock: 100, LastBlock: 109, Status: EpochStatusOpen},
+		},
+	}
+	openRepository = func(ctx context.Context) (inputRepository, func(), error) {
+		return s.repository, func() {}, nil
+	}
+}
+
+func (s *InputSuite) TestShowDecodesTheInput() {
+	out, err := s.execute("show", "--app", testApp.Hex(), "--index", "1", "-o", "json")
+	s.Require().Nil(err)
+
+	var view inputView
+	s.Require().Nil(json.Unmarshal(out, &view))
+	s.Equal(InputStatusAccepted, view.Status)
+	s.Equal(sourceEspresso, view.Source)
+	s.Equal(hexutil.Bytes(common.FromHex("0xfeedbeef")), view.TransactionId)
+	s.Require().NotNil(view.Epoch)
+	s.Equal(uint64(10), view.Epoch.Index)
+	s.Require().NotNil(view.Decoded)
+	s.Equal(uint64(11155111), view.Decoded.ChainId)
+	s.Equal(uint64(1), view.Decoded.Index)
+	s.Equal(hexutil.Bytes(common.FromHex("0xdeadbeef")), view.Decoded.Payload)
+
+	out, err = s.execute("show", "--app", testApp.Hex(), "--index", "1")
+	s.Require().Nil(err)
+	s.Contains(string(out), "Payload:")
+	s.Contains(string(out), "0xdeadbeef")
+	s.Contains(string(out), "10 (blocks 100 to 109, OPEN)")
+}
+
+func (s *InputSuite) TestShowPrintsInputsItCantDecode() {
+	out, err := s.execute("show", "--app", testApp.Hex(), "--index", "0", "-o", "json")
+	s.Require().Nil(err)
+
+	var view inputView
+	s.Require().Nil(json.Unmarshal(out, &view))
+	s.Equal(sourceInputBox, view.Source)
+	s.Nil(view.Decoded)
+	s.NotEmpty(view.DecodeError)
+	s.Equal(hexutil.Bytes(common.FromHex("0x1234")), view.RawData)
+}
+
+func (s *InputSuite) TestShowFailsForMissingInputs() {
+	_, err := s.execute("show", "--app", testApp.Hex(), "--index", "2")
+	s.ErrorContains(err, "has no input 2")
+
+	_, err = s.execute("show", "--app", "0x1", "--index", "0")
+	s.ErrorContains(err, "--app must be an address")
+}
+
+func (s *InputSuite) execute(args ...string) ([]byte, error) {
+	var out bytes.Buffer
+	Cmd.SetArgs(args)
+	Cmd.SetOut(&out)
+	Cmd.SetErr(&bytes.Buffer{})
+	err := Cmd.Execute()
+
+	// flags keep their values between executions
+	reset := func(flag *pflag.Flag) {
+		flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	}
+	Cmd.PersistentFlags().VisitAll(reset)
+	showCmd.Flags().VisitAll(reset)
+	return out.Bytes(), err
+}
+
+type fakeRepository struct {
+	inputs []*Input
+	epochs []Epoch
+}
+
+func (r *fakeRepository) GetInput(ctx context.Context, app Address, index uint64) (*Input, error) {
+	for _, input := range r.inputs {
+		if input.AppAddress == app && input.Index == index {
+			return input, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepository) GetEpochs(ctx context.Context, app Address) ([]Epoch, error) {
+	return r.epochs, nil
+}
@@ -0,0 +1,214 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package send implements the command that signs and submits an input to an application.
+package send
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader/service"
+	"github.com/ZzzzHui/espresso-reader/pkg/espressoclient"
+
+	"github.com/EspressoSystems/espresso-sequencer-go/client"
+	"github.com/EspressoSystems/espresso-sequencer-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "send",
+	Short: "Signs and submits an input to an application",
+	Long: `Signs and submits an input to an application.
+
+The nonce and the chain id are fetched from the Espresso service, unless set with flags.
+The input is submitted through the Espresso service, or directly to Espresso with --espresso-url.
+
+The input is signed with the key set by the flags. Without key flags, the key is read
+from the CARTESI_AUTH_* environment variables, like the node does.`,
+	Example: `espresso-reader send --app 0x2E663fe9aE92275242406A185AA4fC8174339D3E --payload 0xdeadbeef \
+	--mnemonic "test test test test test test test test test test test junk" --wait`,
+	RunE: run,
+}
+
+var (
+	app            string
+	payload        string
+	serviceUrl     string
+	apiKey         string
+	espressoUrl    string
+	namespace      uint64
+	chainId        uint64
+	nonce          uint64
+	privateKey     string
+	privateKeyFile string
+	mnemonic       string
+	mnemonicFile   string
+	accountIndex   uint32
+	wait           bool
+	timeout        time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&app, "app", "", "Application address")
+	Cmd.Flags().StringVar(&payload, "payload", "", "Hex-encoded input payload")
+	Cmd.Flags().StringVar(&serviceUrl, "service-url", "",
+		"Espresso service URL (default: from ESPRESSO_SERVICE_ENDPOINT)")
+	Cmd.Flags().StringVar(&apiKey, "api-key", "", "API key of the Espresso service, if it requires one")
+	Cmd.Flags().StringVar(&espressoUrl, "espresso-url", "",
+		"Espresso base URL, as in ESPRESSO_BASE_URL. If set, the input is submitted directly to Espresso")
+	Cmd.Flags().Uint64Var(&namespace, "namespace", 0, "Espresso namespace, required with --espresso-url")
+	Cmd.Flags().Uint64Var(&chainId, "chain-id", 0, "Chain id of the signed message (default: from the service)")
+	Cmd.Flags().Uint64Var(&nonce, "nonce", 0, "Nonce of the signed message (default: from the service)")
+	Cmd.Flags().StringVar(&privateKey, "private-key", "", "Hex-encoded private key of the sender")
+	Cmd.Flags().StringVar(&privateKeyFile, "private-key-file", "", "File with the private key of the sender")
+	Cmd.Flags().StringVar(&mnemonic, "mnemonic", "", "Mnemonic of the sender")
+	Cmd.Flags().StringVar(&mnemonicFile, "mnemonic-file", "", "File with the mnemonic of the sender")
+	Cmd.Flags().Uint32Var(&accountIndex, "account-index", 0, "Account index of the mnemonic")
+	Cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the reader to ingest the input")
+	Cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "How long to wait for the input with --wait")
+
+	cobra.CheckErr(Cmd.MarkFlagRequired("app"))
+	cobra.CheckErr(Cmd.MarkFlagRequired("payload"))
+	Cmd.MarkFlagsMutuallyExclusive("private-key", "private-key-file", "mnemonic", "mnemonic-file")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if !common.IsHexAddress(app) {
+		return fmt.Errorf("--app must be an address, got '%s'", app)
+	}
+	appAddress := common.HexToAddress(app)
+	data, err := hexutil.Decode(payload)
+	if err != nil {
+		return fmt.Errorf("--payload must be a 0x-prefixed hex string: %w", err)
+	}
+	if espressoUrl != "" && !cmd.Flags().Changed("namespace") {
+		return fmt.Errorf("--namespace is required with --espresso-url")
+	}
+
+	signer, err := newSigner(cmd)
+	if err != nil {
+		return err
+	}
+	var options []espressoclient.ClientOption
+	if apiKey != "" {
+		options = append(options, espressoclient.WithRequestEditorFn(
+			func(ctx context.Context, req *http.Request) error {
+				req.Header.Set(service.HeaderApiKey, apiKey)
+				return nil
+			}))
+	}
+	sender, err := espressoclient.NewInputSender(cliutil.ServiceUrl(serviceUrl), signer, options...)
+	if err != nil {
+		return err
+	}
+
+	message := espressoclient.Message{App: appAddress, Nonce: nonce, Data: data}
+	if !cmd.Flags().Changed("nonce") {
+		message.Nonce, err = sender.Nonce(ctx, appAddress)
+		if err != nil {
+			return fmt.Errorf("failed to get nonce: %w", err)
+		}
+	}
+	if !cmd.Flags().Changed("chain-id") {
+		domain, err := sender.Domain(ctx, appAddress)
+		if err != nil {
+			return fmt.Errorf("failed to get domain: %w", err)
+		}
+		chainId = domain.ChainId
+	}
+	signed, err := espressoclient.SignMessage(signer, chainId, message)
+	if err != nil {
+		return err
+	}
+
+	var txId string
+	if espressoUrl != "" {
+		txId, err = submitToEspresso(ctx, signed)
+	} else {
+		txId, err = sender.Submit(ctx, signed)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to submit input: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "submitted input from %s with nonce %d: %s\n",
+		signer.Address(), message.Nonce, txId)
+
+	if !wait {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	input, err := sender.WaitForInput(ctx, txId)
+	if err != nil {
+		return fmt.Errorf("failed waiting for the input: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "input %d ingested at block %d (%s)\n",
+		input.Index, input.BlockNumber, input.Status)
+	return nil
+}
+
+func submitToEspresso(ctx context.Context, signed *espressoclient.SignedMessage) (string, error) {
+	payload, err := espressoclient.EncodePayload(signed)
+	if err != nil {
+		return "", err
+	}
+	client := client.NewClient(strings.TrimSuffix(espressoUrl, "/") + "/v0")
+	_, err = client.SubmitTransaction(ctx, types.Transaction{Namespace: namespace, Payload: payload})
+	if err != nil {
+		return "", err
+	}
+	return espressoclient.TransactionIdOf(signed)
+}
+
+// newSigner returns the signer set by the flags, or by the CARTESI_AUTH_* variables
+func newSigner(cmd *cobra.Command) (espressoclient.Signer, error) {
+	switch {
+	case privateKey != "":
+		return espressoclient.NewPrivateKeySigner(privateKey)
+	case privateKeyFile != "":
+		contents, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private-key file: %w", err)
+		}
+		return espressoclient.NewPrivateKeySigner(string(contents))
+	case mnemonic != "":
+		return espressoclient.NewMnemonicSigner(mnemonic, accountIndex)
+	case mnemonicFile != "":
+		contents, err := os.ReadFile(mnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mnemonic file: %w", err)
+		}
+		return espressoclient.NewMnemonicSigner(string(contents), accountIndex)
+	}
+	if cmd.Flags().Changed("account-index") {
+		return nil, fmt.Errorf("--account-index requires --mnemonic or --mnemonic-file")
+	}
+	return signerFromAuth(config.AuthFromEnv())
+}
+
+// signerFromAuth returns a signer for the keys the node is configured with
+func signerFromAuth(auth config.Auth) (espressoclient.Signer, error) {
+	switch auth := auth.(type) {
+	case config.AuthPrivateKey:
+		return espressoclient.NewPrivateKeySigner(auth.PrivateKey.Value)
+	case config.AuthMnemonic:
+		if auth.AccountIndex.Value < 0 {
+			return nil, fmt.Errorf("invalid account index %d", auth.AccountIndex.Value)
+		}
+		return espressoclient.NewMnemonicSigner(auth.Mnemonic.Value, uint32(auth.AccountIndex.Value))
+	case config.AuthAWS:
+		return nil, fmt.Errorf("AWS KMS keys are not supported, use a private key or a mnemonic")
+	default:
+		return nil, fmt.Errorf("unknown auth kind %T", auth)
+	}
+}
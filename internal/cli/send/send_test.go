@@ -0,0 +1,60 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package send
+
+import (
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/suite"
+)
+
+type SendSuite struct {
+	suite.Suite
+}
+
+func TestSendSuite(t *testing.T) {
+	suite.Run(t, new(SendSuite))
+}
+
+func (s *SendSuite) TestSignerFromAuth() {
+	expected := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	signer, err := signerFromAuth(config.AuthMnemonic{
+		Mnemonic:     config.Redacted[string]{Value: "test test test test test test test test test test test junk"},
+		AccountIndex: config.Redacted[int]{Value: 1},
+	})
+	s.Require().Nil(err)
+	s.Equal(expected, signer.Address())
+
+	signer, err = signerFromAuth(config.AuthPrivateKey{
+		PrivateKey: config.Redacted[string]{
+			Value: "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d\n",
+		},
+	})
+	s.Require().Nil(err)
+	s.Equal(expected, signer.Address())
+
+	_, err = signerFromAuth(config.AuthAWS{})
+	s.Error(err)
+}
+
+func (s *SendSuite) TestItValidatesFlags() {
+	for _, args := range [][]string{
+		{"--app", "0x1", "--payload", "0x"},
+		{"--app", "0x2E663fe9aE92275242406A185AA4fC8174339D3E", "--payload", "zz"},
+		{"--app", "0x2E663fe9aE92275242406A185AA4fC8174339D3E", "--payload", "0x",
+			"--espresso-url", "http://localhost:1234"},
+	} {
+		Cmd.SetArgs(args)
+		s.Error(Cmd.Execute(), args)
+		Cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package status implements the command that reports how far behind the reader is.
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/cli/cliutil"
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader/service"
+	"github.com/ZzzzHui/espresso-reader/pkg/espressoclient"
+
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "status",
+	Short: "Reports how far behind the reader is",
+	Long: `Reports how far behind the reader is, for each application.
+
+The status is read from the /status endpoint of the running Espresso service:
+the blocks each application was read up to versus the L1 finalized head,
+the Espresso cursor versus the latest Espresso height, the next input index,
+the open epoch and the last error the reader logged.`,
+	Example: `espresso-reader status
+espresso-reader status --service-url http://localhost:8080 -o json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+var (
+	serviceUrl string
+	apiKey     string
+	output     string
+	timeout    time.Duration
+)
+
+func init() {
+	Cmd.Flags().StringVar(&serviceUrl, "service-url", "",
+		"Espresso service URL (default: from ESPRESSO_SERVICE_ENDPOINT)")
+	Cmd.Flags().StringVar(&apiKey, "api-key", "", "API key of the Espresso service, if it requires one")
+	Cmd.Flags().StringVarP(&output, "output", "o", cliutil.OutputTable, "Output format, table or json")
+	Cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "How long to wait for the service")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	if err := cliutil.ValidateOutput(output); err != nil {
+		return err
+	}
+
+	url := cliutil.ServiceUrl(serviceUrl)
+	var options []espressoclient.ClientOption
+	if apiKey != "" {
+		options = append(options, espressoclient.WithRequestEditorFn(
+			func(ctx context.Context, req *http.Request) error {
+				req.Header.Set(service.HeaderApiKey, apiKey)
+				return nil
+			}))
+	}
+	client, err := espressoclient.NewClientWithResponses(url, options...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+	response, err := client.GetStatusWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get status from %s: %w", url, err)
+	}
+	if response.JSON200 == nil {
+		return fmt.Errorf("failed to get status from %s: %d %s",
+			url, response.StatusCode(), response.Body)
+	}
+
+	if output == cliutil.OutputJSON {
+		return cliutil.PrintJSON(cmd.OutOrStdout(), response.JSON200)
+	}
+	return printStatus(cmd.OutOrStdout(), response.JSON200)
+}
+
+func printStatus(w io.Writer, status *espressoclient.SyncStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "L1 finalized block:\t%s\n", optional(status.L1FinalizedBlock))
+	fmt.Fprintf(tw, "Espresso latest height:\t%s\n", optional(status.EspressoLatestHeight))
+	if status.LastError != nil {
+		fmt.Fprintf(tw, "Last error:\t%s\n", formatError(status.LastError))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(status.Applications) == 0 {
+		fmt.Fprintln(w, "\nNo applications registered")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "APPLICATION\tSTATUS\tL1 BLOCK\tL1 LAG\tCLAIM CHECK\tOUTPUT CHECK\t"+
+		"ESPRESSO BLOCK\tESPRESSO LAG\tNEXT INPUT\tOPEN EPOCH\tLAST ERROR")
+	for _, app := range status.Applications {
+		openEpoch := "-"
+		if app.OpenEpoch != nil {
+			openEpoch = fmt.Sprintf("%d (%d-%d)",
+				app.OpenEpoch.Index, app.OpenEpoch.FirstBlock, app.OpenEpoch.LastBlock)
+		}
+		lastError := "-"
+		if app.LastError != nil {
+			lastError = formatError(app.LastError)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%d\t%d\t%s\t%d\t%s\t%s\n",
+			app.ContractAddress, app.Status,
+			app.LastProcessedBlock, optional(app.L1Lag),
+			app.LastClaimCheckBlock, app.LastOutputCheckBlock,
+			app.LastProcessedEspressoBlock, optional(app.EspressoLag),
+			app.NextInputIndex, openEpoch, lastError)
+	}
+	return tw.Flush()
+}
+
+// optional formats values the service couldn't find out, like unreachable heads
+func optional(value *uint64) string {
+	if value == nil {
+		return "unknown"
+	}
+	return fmt.Sprint(*value)
+}
+
+func formatError(record *espressoclient.ErrorRecord) string {
+	return fmt.Sprintf("%s (%s)", record.Message, record.Time.Local().Format(time.DateTime))
+}
@@ -0,0 +1,135 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader/service"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/suite"
+)
+
+const testStatus = `{
+	"l1_finalized_block": 100,
+	"espresso_latest_height": 1500,
+	"applications": [{
+		"contract_address": "0x2E663fe9aE92275242406A185AA4fC8174339D3E",
+		"status": "RUNNING",
+		"last_processed_block": 90,
+		"last_claim_check_block": 80,
+		"last_output_check_block": 70,
+		"l1_lag": 10,
+		"last_processed_espresso_block": 1000,
+		"espresso_lag": 500,
+		"next_input_index": 7,
+		"open_epoch": {"index": 9, "first_block": 90, "last_block": 99},
+		"last_error": {"message": "failed reading inputs: timeout", "time": "2024-06-10T12:00:00Z"}
+	}]
+}`
+
+type StatusSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	requests []*http.Request
+}
+
+func TestStatusSuite(t *testing.T) {
+	suite.Run(t, new(StatusSuite))
+}
+
+func (s *StatusSuite) SetupTest() {
+	s.requests = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(testStatus))
+	}))
+}
+
+func (s *StatusSuite) TearDownTest() {
+	s.server.Close()
+	Cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	})
+}
+
+func (s *StatusSuite) execute(args ...string) (string, error) {
+	var out bytes.Buffer
+	Cmd.SetOut(&out)
+	Cmd.SetErr(&out)
+	Cmd.SetArgs(append([]string{"--service-url", s.server.URL}, args...))
+	err := Cmd.Execute()
+	return out.String(), err
+}
+
+func (s *StatusSuite) TestItPrintsTheStatus() {
+	out, err := s.execute("--api-key", "secret")
+	s.Require().Nil(err, out)
+	s.Contains(out, "L1 finalized block:      100")
+	s.Contains(out, "Espresso latest height:  1500")
+	s.Contains(out, "0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+	s.Contains(out, "9 (90-99)")
+	s.Contains(out, "failed reading inputs: timeout")
+
+	s.Require().Len(s.requests, 1)
+	s.Equal("secret", s.requests[0].Header.Get(service.HeaderApiKey))
+}
+
+func (s *StatusSuite) TestItPrintsJSON() {
+	out, err := s.execute("-o", "json")
+	s.Require().Nil(err, out)
+	var expected, actual any
+	s.Require().Nil(json.Unmarshal([]byte(testStatus), &expected))
+	s.Require().Nil(json.Unmarshal([]byte(out), &actual))
+	s.Equal(expected, actual)
+}
+
+func (s *StatusSuite) TestItReadsTheServiceEndpointWhenItRuns() {
+	s.T().Setenv("ESPRESSO_SERVICE_ENDPOINT", strings.TrimPrefix(s.server.URL, "http://"))
+	var out bytes.Buffer
+	Cmd.SetOut(&out)
+	Cmd.SetErr(&out)
+	Cmd.SetArgs([]string{})
+	s.Require().Nil(Cmd.Execute(), out.String())
+	s.Len(s.requests, 1)
+}
+
+func (s *StatusSuite) TestItUsesHTTPSWhenTheServiceServesIt() {
+	server := httptest.NewTLSServer(s.server.Config.Handler)
+	defer server.Close()
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	defer func() { http.DefaultTransport = transport }()
+	s.T().Setenv("ESPRESSO_SERVICE_ENDPOINT", strings.TrimPrefix(server.URL, "https://"))
+	s.T().Setenv("ESPRESSO_SERVICE_TLS_CERT_FILE", "cert.pem")
+	s.T().Setenv("ESPRESSO_SERVICE_TLS_KEY_FILE", "key.pem")
+
+	var out bytes.Buffer
+	Cmd.SetOut(&out)
+	Cmd.SetErr(&out)
+	Cmd.SetArgs([]string{})
+	s.Require().Nil(Cmd.Execute(), out.String())
+	s.Len(s.requests, 1)
+}
+
+func (s *StatusSuite) TestItFailsWhenTheServiceFails() {
+	s.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":"internal_error","message":"failed to get status"}`,
+			http.StatusInternalServerError)
+	})
+	_, err := s.execute()
+	s.ErrorContains(err, "500")
+}
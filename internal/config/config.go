@@ -1,13 +1,14 @@
 // (c) Cartesi and individual authors (see AUTHORS)
 // SPDX-License-Identifier: Apache-2.0 (see LICENSE)
 
-// The config package manages the node configuration, which comes from environment variables.
-// The sub-package generate specifies these environment variables.
+// The config package manages the node configuration, which comes from command-line flags,
+// environment variables and a config file, in this order of precedence.
+// The sub-package generate specifies these variables.
 package config
 
 import (
 	"fmt"
-	"os"
+	"strings"
 )
 
 // NodeConfig contains all the Node variables.
@@ -15,9 +16,14 @@ import (
 type NodeConfig struct {
 	LogLevel                               LogLevel
 	LogPrettyEnabled                       bool
+	LogFormat                              string
+	LogFile                                string
+	LogFileMaxSize                         uint64
+	LogFileMaxBackups                      uint64
 	BlockchainID                           uint64
 	BlockchainHttpEndpoint                 Redacted[string]
 	BlockchainWsEndpoint                   Redacted[string]
+	BlockchainPollingInterval              Duration
 	LegacyBlockchainEnabled                bool
 	EvmReaderDefaultBlock                  DefaultBlock
 	EvmReaderRetryPolicyMaxRetries         uint64
@@ -27,6 +33,7 @@ type NodeConfig struct {
 	ContractsInputBoxDeploymentBlockNumber int64
 	SnapshotDir                            string
 	PostgresEndpoint                       Redacted[string]
+	PostgresAutoMigrate                    bool
 	HttpAddress                            string
 	HttpPort                               int
 	FeatureClaimSubmissionEnabled          bool
@@ -39,6 +46,30 @@ type NodeConfig struct {
 	EspressoStartingBlock                  uint64
 	EspressoNamespace                      uint64
 	EspressoServiceEndpoint                string
+	EspressoServiceReadTimeout             Duration
+	EspressoServiceWriteTimeout            Duration
+	EspressoServiceShutdownTimeout         Duration
+	EspressoServiceTlsCertFile             string
+	EspressoServiceTlsKeyFile              string
+	EspressoServiceCorsAllowedOrigins      []string
+	EspressoServiceTrustedProxies          []string
+	EspressoServiceMaxRequestSize          int64
+	EspressoServiceApiKeys                 Redacted[string]
+	EspressoServiceApiKeysFile             string
+	EspressoServiceAnonymousScopes         string
+	EspressoServiceAuthMaxClockSkew        Duration
+	EspressoNoncePendingTimeout            Duration
+	EspressoMaxPayloadSize                 uint64
+	EspressoSubmitRateLimitPerIp           uint64
+	EspressoSubmitBurstPerIp               uint64
+	EspressoSubmitRateLimitPerSender       uint64
+	EspressoSubmitBurstPerSender           uint64
+	EspressoSubmitRateLimitPerApp          uint64
+	EspressoSubmitBurstPerApp              uint64
+	EspressoMaxInputsPerBlock              uint64
+	EspressoMaxInputsPerSenderPerBlock     uint64
+	EspressoReadyMaxLag                    uint64
+	EspressoTracingExporter                string
 }
 
 // Auth is used to sign transactions.
@@ -70,14 +101,20 @@ func (r Redacted[T]) String() string {
 	return "[REDACTED]"
 }
 
-// FromEnv loads the config from environment variables.
+// FromEnv loads the config from the flags, the environment variables and the config file.
+// See Lookup for the precedence between them.
 func FromEnv() NodeConfig {
 	var config NodeConfig
 	config.LogLevel = GetLogLevel()
 	config.LogPrettyEnabled = GetLogPrettyEnabled()
+	config.LogFormat = GetLogFormat()
+	config.LogFile = GetLogFile()
+	config.LogFileMaxSize = GetLogFileMaxSize()
+	config.LogFileMaxBackups = GetLogFileMaxBackups()
 	config.BlockchainID = GetBlockchainId()
 	config.BlockchainHttpEndpoint = Redacted[string]{GetBlockchainHttpEndpoint()}
 	config.BlockchainWsEndpoint = Redacted[string]{GetBlockchainWsEndpoint()}
+	config.BlockchainPollingInterval = GetBlockchainPollingInterval()
 	config.LegacyBlockchainEnabled = GetLegacyBlockchainEnabled()
 	config.EvmReaderDefaultBlock = GetEvmReaderDefaultBlock()
 	config.EvmReaderRetryPolicyMaxRetries = GetEvmReaderRetryPolicyMaxRetries()
@@ -87,6 +124,7 @@ func FromEnv() NodeConfig {
 	config.ContractsInputBoxDeploymentBlockNumber = GetContractsInputBoxDeploymentBlockNumber()
 	config.SnapshotDir = GetSnapshotDir()
 	config.PostgresEndpoint = Redacted[string]{GetPostgresEndpoint()}
+	config.PostgresAutoMigrate = GetPostgresAutoMigrate()
 	config.HttpAddress = GetHttpAddress()
 	config.HttpPort = GetHttpPort()
 	config.FeatureClaimSubmissionEnabled = GetFeatureClaimSubmissionEnabled()
@@ -101,9 +139,44 @@ func FromEnv() NodeConfig {
 	config.EspressoStartingBlock = GetStartingBlock()
 	config.EspressoNamespace = GetNamespace()
 	config.EspressoServiceEndpoint = GetServiceEndpoint()
+	config.EspressoServiceReadTimeout = GetServiceReadTimeout()
+	config.EspressoServiceWriteTimeout = GetServiceWriteTimeout()
+	config.EspressoServiceShutdownTimeout = GetServiceShutdownTimeout()
+	config.EspressoServiceTlsCertFile = GetServiceTlsCertFile()
+	config.EspressoServiceTlsKeyFile = GetServiceTlsKeyFile()
+	config.EspressoServiceCorsAllowedOrigins = splitList(GetServiceCorsAllowedOrigins())
+	config.EspressoServiceTrustedProxies = splitList(GetServiceTrustedProxies())
+	config.EspressoServiceMaxRequestSize = GetServiceMaxRequestSize()
+	config.EspressoServiceApiKeys = Redacted[string]{GetServiceApiKeys()}
+	config.EspressoServiceApiKeysFile = GetServiceApiKeysFile()
+	config.EspressoServiceAnonymousScopes = GetServiceAnonymousScopes()
+	config.EspressoServiceAuthMaxClockSkew = GetServiceAuthMaxClockSkew()
+	config.EspressoNoncePendingTimeout = GetNoncePendingTimeout()
+	config.EspressoMaxPayloadSize = GetMaxPayloadSize()
+	config.EspressoSubmitRateLimitPerIp = GetSubmitRateLimitPerIp()
+	config.EspressoSubmitBurstPerIp = GetSubmitBurstPerIp()
+	config.EspressoSubmitRateLimitPerSender = GetSubmitRateLimitPerSender()
+	config.EspressoSubmitBurstPerSender = GetSubmitBurstPerSender()
+	config.EspressoSubmitRateLimitPerApp = GetSubmitRateLimitPerApp()
+	config.EspressoSubmitBurstPerApp = GetSubmitBurstPerApp()
+	config.EspressoMaxInputsPerBlock = GetMaxInputsPerBlock()
+	config.EspressoMaxInputsPerSenderPerBlock = GetMaxInputsPerSenderPerBlock()
+	config.EspressoReadyMaxLag = GetReadyMaxLag()
+	config.EspressoTracingExporter = GetTracingExporter()
 	return config
 }
 
+// splitList splits a comma-separated list, ignoring blank items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func AuthFromEnv() Auth {
 	switch GetAuthKind() {
 	case AuthKindPrivateKeyVar:
@@ -111,13 +184,12 @@ func AuthFromEnv() Auth {
 			PrivateKey: Redacted[string]{GetAuthPrivateKey()},
 		}
 	case AuthKindPrivateKeyFile:
-		path := GetAuthPrivateKeyFile()
-		privateKey, err := os.ReadFile(path)
+		privateKey, err := readSecretFile(GetAuthPrivateKeyFile())
 		if err != nil {
 			panic(fmt.Sprintf("failed to read private-key file: %v", err))
 		}
 		return AuthPrivateKey{
-			PrivateKey: Redacted[string]{string(privateKey)},
+			PrivateKey: Redacted[string]{privateKey},
 		}
 	case AuthKindMnemonicVar:
 		return AuthMnemonic{
@@ -125,13 +197,12 @@ func AuthFromEnv() Auth {
 			AccountIndex: Redacted[int]{GetAuthMnemonicAccountIndex()},
 		}
 	case AuthKindMnemonicFile:
-		path := GetAuthMnemonicFile()
-		mnemonic, err := os.ReadFile(path)
+		mnemonic, err := readSecretFile(GetAuthMnemonicFile())
 		if err != nil {
 			panic(fmt.Sprintf("failed to read mnemonic file: %v", err))
 		}
 		return AuthMnemonic{
-			Mnemonic:     Redacted[string]{string(mnemonic)},
+			Mnemonic:     Redacted[string]{mnemonic},
 			AccountIndex: Redacted[int]{GetAuthMnemonicAccountIndex()},
 		}
 	case AuthKindAWS:
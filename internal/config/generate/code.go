@@ -6,6 +6,7 @@ package main
 import (
 	"bytes"
 	"os"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -15,7 +16,8 @@ import (
 	"golang.org/x/text/language"
 )
 
-// generateCodeFile generates a Go file with the getters for the config variables.
+// generateCodeFile generates a Go file with the getters for the config variables
+// and the table of variables the flags and the config file are made from.
 func generateCodeFile(path string, env []Env) {
 	// Load template
 	funcMap := template.FuncMap{
@@ -30,6 +32,23 @@ func generateCodeFile(path string, env []Env) {
 		"toGoFunc": func(goType string) string {
 			return "to" + strings.ToUpper(goType[:1]) + goType[1:]
 		},
+		"toKey": func(env string) string {
+			words := strings.Split(strings.ToLower(env), "_")
+			return strings.Join(words[1:], "-")
+		},
+		"toUsage": func(env Env) string {
+			// the first sentence, without the backticks pflag takes as the flag type
+			paragraph, _, _ := strings.Cut(env.Description, "\n\n")
+			usage := strings.Join(strings.Fields(paragraph), " ")
+			if i := strings.Index(usage, ". "); i >= 0 {
+				usage = usage[:i+1]
+			}
+			usage = strings.ReplaceAll(usage, "`", "")
+			return strconv.Quote(usage + " (" + env.Name + ")")
+		},
+		"toDefault": func(value *string) string {
+			return strconv.Quote(*value)
+		},
 	}
 	tmpl := template.Must(template.New("code").Funcs(funcMap).Parse(codeTemplate))
 
@@ -65,7 +84,6 @@ package config
 import (
 	"fmt"
 	"log/slog"
-	"os"
 	"strconv"
 	"time"
 
@@ -172,19 +190,45 @@ var (
 	toDefaultBlock = ToDefaultBlockFromString
 )
 
+// ------------------------------------------------------------------------------------------------
+// Variables
+// ------------------------------------------------------------------------------------------------
+
+// Variables lists the config variables, sorted by topic and name.
+var Variables = []Variable{
+{{- range .}}
+	{
+		Name:   "{{.Name}}",
+		Key:    "{{toKey .Name}}",
+		GoType: "{{.GoType}}",
+		{{- if .Default}}
+		Default:    {{toDefault .Default}},
+		HasDefault: true,
+		{{- end}}
+		{{- if .Redact}}
+		Redact: true,
+		{{- end}}
+		{{- if .Reloadable}}
+		Reloadable: true,
+		{{- end}}
+		{{- if .SecretFile}}
+		SecretFile: "{{.SecretFile}}",
+		{{- end}}
+		Usage: {{toUsage .}},
+		parse: parser({{toGoFunc .GoType}}),
+	},
+{{- end}}
+}
+
 // ------------------------------------------------------------------------------------------------
 // Getters
 // ------------------------------------------------------------------------------------------------
 
 {{range .}}
 func Get{{toFunctionName .Name}}() {{.GoType}} {
-	s, ok := os.LookupEnv("{{.Name}}")
-	if !ok {
-		{{- if .Default}}
-		s = "{{.Default}}"
-		{{- else}}
-		panic("missing env var {{.Name}}")
-		{{- end}}
+	s, _, err := Lookup("{{.Name}}")
+	if err != nil {
+		panic(err.Error())
 	}
 	val, err := {{toGoFunc .GoType}}(s)
 	if err != nil {
@@ -192,4 +236,4 @@ func Get{{toFunctionName .Name}}() {{.GoType}} {
 	}
 	return val
 }
-{{end}}`
+{{end}}`
\ No newline at end of file
@@ -5,6 +5,7 @@ package main
 
 import (
 	"os"
+	"strings"
 	"text/template"
 )
 
@@ -25,6 +26,10 @@ func generateDocsFile(path string, env []Env) {
 		"quote": func(s string) string {
 			return `"` + s + `"`
 		},
+		"flag": func(env string) string {
+			words := strings.Split(strings.ToLower(env), "_")
+			return "--" + strings.Join(words[1:], "-")
+		},
 	}
 	tmpl := template.Must(template.New("docs").Funcs(funcMap).Parse(docsTemplate))
 
@@ -43,8 +48,15 @@ DO NOT EDIT.
 <!-- markdownlint-disable line_length -->
 # Node Configuration
 
-The node is configurable through environment variables.
-(There is no other way to configure it.)
+The node is configurable through command-line flags, environment variables and a config file,
+in this order of precedence, falling back to the defaults below.
+
+Each variable has a flag, which is also its key in the config file.
+The config file is passed with ` + "`--config`" + ` and is in TOML or YAML, according to its extension.
+Durations are in seconds and lists are comma-separated, as in the environment.
+
+The reloadable variables take effect on ` + "`SIGHUP`" + `, or when the config file changes,
+without restarting the node. Changes to the other variables are logged and ignored until it restarts.
 
 This file documents the configuration options.
 
@@ -56,8 +68,12 @@ This file documents the configuration options.
 {{.Description}}
 
 * **Type:** {{backtick .GoType}}
+* **Flag:** {{backtick (flag .Name)}}
 {{- if .Default}}
 * **Default:** {{.Default | quote | backtick}}
 {{- end}}
+{{- if .Reloadable}}
+* **Reloadable:** on ` + "`SIGHUP`" + ` or when the config file changes
+{{- end}}
 {{- end}}
 `
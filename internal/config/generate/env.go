@@ -19,6 +19,17 @@ type Env struct {
 	// A brief description of the environment variable.
 	// This field is required.
 	Description string `toml:"description"`
+
+	// Whether the value is a secret, hidden when the config is printed.
+	// This field is optional.
+	Redact bool `toml:"redact"`
+
+	// Whether the value can change while the node runs, on SIGHUP or when the config file changes.
+	// This field is optional.
+	Reloadable bool `toml:"reloadable"`
+
+	// The generated variable with the path to a file holding the secret value.
+	SecretFile string `toml:"-"`
 }
 
 // Validates whether the fields of the environment variables were initialized correctly
@@ -31,3 +42,32 @@ func (e *Env) validate() {
 		panic("missing description for " + e.Name)
 	}
 }
+
+// Adds a variable with the path to a file holding the value of each redacted variable,
+// after it, unless Config.toml already declares a variable with that name,
+// which then reads the file in its own way.
+func addSecretFiles(envs []Env) []Env {
+	names := map[string]bool{}
+	for _, env := range envs {
+		names[env.Name] = true
+	}
+	var withFiles []Env
+	for _, env := range envs {
+		name := env.Name + "_FILE"
+		if !env.Redact || names[name] {
+			withFiles = append(withFiles, env)
+			continue
+		}
+		env.SecretFile = name
+		withFiles = append(withFiles, env)
+		empty := ""
+		withFiles = append(withFiles, Env{
+			Name:    name,
+			Default: &empty,
+			GoType:  "string",
+			Description: "Path to a file with the value of `" + env.Name + "`, such as a Docker or Kubernetes secret.\n" +
+				"Its contents are trimmed, and only used when `" + env.Name + "` is not set.",
+		})
+	}
+	return withFiles
+}
@@ -4,7 +4,7 @@
 //go:generate go run .
 
 // This script will read the Config.toml file and create:
-// - a formatted get.go file, with get functions for each environment variable;
+// - a formatted generated.go file, with get functions and a table entry for each variable;
 // - a config.md file with documentation for the environment variables.
 //
 // Each table entry in the toml file translates into an environment variable.
@@ -19,6 +19,7 @@ func main() {
 	for _, env := range envs {
 		env.validate()
 	}
+	envs = addSecretFiles(envs)
 	generateDocsFile("../../../docs/config.md", envs)
 	generateCodeFile("../generated.go", envs)
 }
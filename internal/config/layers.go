@@ -0,0 +1,210 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is where the value of a config variable comes from.
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+)
+
+// Variable is a config variable, as specified in generate/Config.toml.
+type Variable struct {
+	Name       string // name of the environment variable
+	Key        string // name of the flag and key in the config file
+	GoType     string
+	Default    string
+	HasDefault bool
+	Redact     bool   // whether the value is a secret
+	Reloadable bool   // whether the value can change while the node runs
+	SecretFile string // name of the variable with the path to a file holding the value, if any
+	Usage      string
+	parse      func(string) error
+}
+
+// parser returns a function that checks whether a value parses with parse.
+func parser[T any](parse func(string) (T, error)) func(string) error {
+	return func(s string) error {
+		_, err := parse(s)
+		return err
+	}
+}
+
+var (
+	// flag sets added by AddFlags, to look the flags up in
+	flagSets []*pflag.FlagSet
+
+	// values loaded by LoadFile, by variable name.
+	// The reloader replaces them while the getters read them, so the map is
+	// swapped whole and never modified.
+	fileValues atomic.Pointer[map[string]string]
+)
+
+// AddFlags adds a flag for each config variable to flags.
+func AddFlags(flags *pflag.FlagSet) {
+	for _, variable := range Variables {
+		flags.String(variable.Key, variable.Default, variable.Usage)
+		if variable.GoType == "bool" {
+			flags.Lookup(variable.Key).NoOptDefVal = "true"
+		}
+	}
+	flagSets = append(flagSets, flags)
+}
+
+// LoadFile loads the config variables from the TOML or YAML file at path,
+// according to its extension, replacing the ones previously loaded.
+// The keys of the file are the names of the flags.
+func LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("config file %s must be .toml, .yaml or .yml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	for key, value := range raw {
+		variable, ok := variableByKey(key)
+		if !ok {
+			return fmt.Errorf("unknown key '%s' in config file %s", key, path)
+		}
+		s, err := fileValue(value)
+		if err != nil {
+			return fmt.Errorf("invalid '%s' in config file %s: %w", key, path, err)
+		}
+		values[variable.Name] = s
+	}
+	fileValues.Store(&values)
+	return nil
+}
+
+// fileValue formats value as it would be set in the environment.
+func fileValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string, bool, int, int64, uint64, float64:
+		return fmt.Sprint(value), nil
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			s, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// ErrUnset is returned by Lookup for the variables set nowhere and without a default.
+var ErrUnset = errors.New("must be set")
+
+// layer is where the value of a variable may come from.
+type layer struct {
+	source Source
+	lookup func(variable Variable) (string, bool)
+}
+
+// Layers by precedence
+var layers = []layer{
+	{SourceFlag, func(variable Variable) (string, bool) {
+		for _, flags := range flagSets {
+			if flag := flags.Lookup(variable.Key); flag != nil && flag.Changed {
+				return flag.Value.String(), true
+			}
+		}
+		return "", false
+	}},
+	{SourceEnv, func(variable Variable) (string, bool) {
+		return os.LookupEnv(variable.Name)
+	}},
+	{SourceFile, func(variable Variable) (string, bool) {
+		values := fileValues.Load()
+		if values == nil {
+			return "", false
+		}
+		s, ok := (*values)[variable.Name]
+		return s, ok
+	}},
+}
+
+// Lookup returns the value of the config variable name and where it comes from.
+// Flags take precedence over the environment, which takes precedence over the config file,
+// which takes precedence over the default.
+// In each of them, a secret may also be read from the file its _FILE variant points to,
+// if the variable itself isn't set.
+// It returns ErrUnset if the variable is set nowhere and has no default.
+func Lookup(name string) (string, Source, error) {
+	variable, ok := variableByName(name)
+	if !ok {
+		return "", "", fmt.Errorf("unknown config variable %s", name)
+	}
+	secretFile, hasSecretFile := variableByName(variable.SecretFile)
+	for _, layer := range layers {
+		if s, ok := layer.lookup(variable); ok {
+			return s, layer.source, nil
+		}
+		if !hasSecretFile {
+			continue
+		}
+		if path, ok := layer.lookup(secretFile); ok && path != "" {
+			s, err := readSecretFile(path)
+			if err != nil {
+				return "", "", fmt.Errorf("%s: %w", secretFile.Name, err)
+			}
+			return s, layer.source, nil
+		}
+	}
+	if variable.HasDefault {
+		return variable.Default, SourceDefault, nil
+	}
+	return "", "", fmt.Errorf("%s: %w", name, ErrUnset)
+}
+
+func variableByName(name string) (Variable, bool) {
+	for _, variable := range Variables {
+		if variable.Name == name {
+			return variable, true
+		}
+	}
+	return Variable{}, false
+}
+
+func variableByKey(key string) (Variable, bool) {
+	for _, variable := range Variables {
+		if variable.Key == key {
+			return variable, true
+		}
+	}
+	return Variable{}, false
+}
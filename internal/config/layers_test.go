@@ -0,0 +1,104 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/suite"
+)
+
+type LayersSuite struct {
+	suite.Suite
+	flags *pflag.FlagSet
+}
+
+func TestLayersSuite(t *testing.T) {
+	suite.Run(t, new(LayersSuite))
+}
+
+func (s *LayersSuite) SetupTest() {
+	s.flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(s.flags)
+}
+
+func (s *LayersSuite) TearDownTest() {
+	flagSets = nil
+	fileValues.Store(nil)
+}
+
+func (s *LayersSuite) writeFile(name, content string) string {
+	path := filepath.Join(s.T().TempDir(), name)
+	s.Require().Nil(os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func (s *LayersSuite) TestItFallsBackToTheDefaults() {
+	value, source, err := Lookup("CARTESI_LOG_LEVEL")
+	s.Nil(err)
+	s.Equal("info", value)
+	s.Equal(SourceDefault, source)
+
+	_, _, err = Lookup("CARTESI_BLOCKCHAIN_ID")
+	s.ErrorIs(err, ErrUnset)
+	s.PanicsWithValue("CARTESI_BLOCKCHAIN_ID: must be set", func() { GetBlockchainId() })
+}
+
+func (s *LayersSuite) TestItAppliesTheLayersInOrder() {
+	s.Require().Nil(LoadFile(s.writeFile("config.toml", `
+log-level = "warn"
+max-payload-size = 2048
+service-read-timeout = 15
+`)))
+	s.T().Setenv("CARTESI_LOG_LEVEL", "error")
+	s.T().Setenv("ESPRESSO_MAX_PAYLOAD_SIZE", "4096")
+	s.Require().Nil(s.flags.Parse([]string{"--log-level", "debug"}))
+
+	s.Equal(slog.LevelDebug, GetLogLevel())
+	s.Equal(uint64(4096), GetMaxPayloadSize())
+	s.Equal(15*time.Second, GetServiceReadTimeout())
+
+	_, source, _ := Lookup("CARTESI_LOG_LEVEL")
+	s.Equal(SourceFlag, source)
+	_, source, _ = Lookup("ESPRESSO_MAX_PAYLOAD_SIZE")
+	s.Equal(SourceEnv, source)
+	_, source, _ = Lookup("ESPRESSO_SERVICE_READ_TIMEOUT")
+	s.Equal(SourceFile, source)
+}
+
+func (s *LayersSuite) TestItLoadsYamlFiles() {
+	s.Require().Nil(LoadFile(s.writeFile("config.yaml", `
+postgres-auto-migrate: true
+service-cors-allowed-origins:
+  - https://a.example
+  - https://b.example
+`)))
+	s.True(GetPostgresAutoMigrate())
+	s.Equal([]string{"https://a.example", "https://b.example"},
+		splitList(GetServiceCorsAllowedOrigins()))
+}
+
+func (s *LayersSuite) TestItParsesBoolFlagsWithoutValue() {
+	s.Require().Nil(s.flags.Parse([]string{"--postgres-auto-migrate"}))
+	s.True(GetPostgresAutoMigrate())
+}
+
+func (s *LayersSuite) TestItRejectsInvalidFiles() {
+	err := LoadFile(s.writeFile("config.toml", `log-levl = "debug"`))
+	s.ErrorContains(err, "unknown key 'log-levl'")
+
+	err = LoadFile(s.writeFile("config.json", `{}`))
+	s.ErrorContains(err, "must be .toml, .yaml or .yml")
+
+	err = LoadFile(s.writeFile("config.yaml", "log-level: {nested: true}"))
+	s.ErrorContains(err, "invalid 'log-level'")
+
+	err = LoadFile(filepath.Join(s.T().TempDir(), "missing.toml"))
+	s.ErrorContains(err, "failed to read config file")
+}
@@ -0,0 +1,154 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// How long to wait for the writes to the config file to settle before reloading it
+const reloadDebounce = 200 * time.Millisecond
+
+// Reloader reloads the config while the node runs, on a signal or when the config file changes.
+// Only the reloadable variables are applied; changes to the others are logged and ignored
+// until the node restarts.
+type Reloader struct {
+	configFile string
+	apply      func(NodeConfig)
+	values     map[string]string // the values in use, by variable name
+}
+
+// NewReloader returns a Reloader of the config loaded from configFile, if any,
+// that hands the new config to apply.
+func NewReloader(configFile string, apply func(NodeConfig)) (*Reloader, error) {
+	values, err := lookupAll()
+	if err != nil {
+		return nil, err
+	}
+	return &Reloader{configFile: configFile, apply: apply, values: values}, nil
+}
+
+// lookupAll returns the values of all the variables that are set
+func lookupAll() (map[string]string, error) {
+	values := map[string]string{}
+	for _, variable := range Variables {
+		value, _, err := Lookup(variable.Name)
+		if errors.Is(err, ErrUnset) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[variable.Name] = value
+	}
+	return values, nil
+}
+
+// Reload loads the config again and applies it if any reloadable variable changed.
+// The current config is kept if the new one is invalid.
+func (r *Reloader) Reload() error {
+	previousFileValues := fileValues.Load()
+	if r.configFile != "" {
+		if err := LoadFile(r.configFile); err != nil {
+			return err
+		}
+	}
+	config, err := Load()
+	if err != nil {
+		fileValues.Store(previousFileValues)
+		return err
+	}
+	values, err := lookupAll()
+	if err != nil {
+		fileValues.Store(previousFileValues)
+		return err
+	}
+
+	var reloaded []string
+	for _, variable := range Variables {
+		name := variable.Name
+		previous, wasSet := r.values[name]
+		value, isSet := values[name]
+		if previous == value && wasSet == isSet {
+			continue
+		}
+		if !variable.Reloadable {
+			slog.Warn("config: ignored a change that needs a restart of the node to take effect",
+				"variable", name)
+			// keep the value in use, so the change is reported until the restart
+			if wasSet {
+				values[name] = previous
+			} else {
+				delete(values, name)
+			}
+			continue
+		}
+		reloaded = append(reloaded, name)
+	}
+	r.values = values
+	if len(reloaded) == 0 {
+		slog.Info("config: reloaded, no reloadable variable changed")
+		return nil
+	}
+	slog.Info("config: reloaded", "variables", reloaded)
+	r.apply(config)
+	return nil
+}
+
+// Run reloads the config on each signal from signals, and when the config file changes,
+// until ctx is done.
+func (r *Reloader) Run(ctx context.Context, signals <-chan os.Signal) error {
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if r.configFile != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+		// editors and Kubernetes replace the file instead of writing to it,
+		// so the directory is watched rather than the file
+		if err := watcher.Add(filepath.Dir(r.configFile)); err != nil {
+			return err
+		}
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case signal := <-signals:
+			slog.Info("config: reloading", "signal", signal)
+			r.reload()
+		case event := <-events:
+			// Kubernetes swaps the ..data link of the mounted ConfigMap
+			name := filepath.Base(event.Name)
+			if !event.Has(fsnotify.Chmod) &&
+				(name == filepath.Base(r.configFile) || name == "..data") {
+				debounce = time.After(reloadDebounce)
+			}
+		case err := <-watchErrors:
+			slog.Error("config: failed to watch the config file", "path", r.configFile, "error", err)
+		case <-debounce:
+			debounce = nil
+			slog.Info("config: reloading", "path", r.configFile)
+			r.reload()
+		}
+	}
+}
+
+func (r *Reloader) reload() {
+	if err := r.Reload(); err != nil {
+		slog.Error("config: kept the current config, since the new one is invalid", "error", err)
+	}
+}
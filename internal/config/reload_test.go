@@ -0,0 +1,158 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReloadSuite struct {
+	suite.Suite
+	path    string
+	applied chan NodeConfig
+	logs    bytes.Buffer
+	logger  *slog.Logger
+}
+
+func TestReloadSuite(t *testing.T) {
+	suite.Run(t, new(ReloadSuite))
+}
+
+func (s *ReloadSuite) SetupTest() {
+	s.T().Setenv("CARTESI_BLOCKCHAIN_ID", "31337")
+	s.T().Setenv("CARTESI_BLOCKCHAIN_HTTP_ENDPOINT", "http://localhost:8545")
+	s.T().Setenv("CARTESI_CONTRACTS_INPUT_BOX_ADDRESS", "0x593E5BCf894D6829Dd26D0810DA7F064406aebB6")
+	s.T().Setenv("CARTESI_CONTRACTS_INPUT_BOX_DEPLOYMENT_BLOCK_NUMBER", "10")
+	s.T().Setenv("CARTESI_FEATURE_CLAIM_SUBMISSION_ENABLED", "false")
+	s.T().Setenv("ESPRESSO_BASE_URL", "http://localhost:24000")
+
+	s.logs.Reset()
+	s.logger = slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&s.logs, nil)))
+
+	s.path = filepath.Join(s.T().TempDir(), "config.toml")
+	s.writeConfig(`log-level = "info"` + "\n" + `namespace = 1`)
+	s.Require().Nil(LoadFile(s.path))
+	s.applied = make(chan NodeConfig, 10)
+}
+
+func (s *ReloadSuite) TearDownTest() {
+	slog.SetDefault(s.logger)
+	flagSets = nil
+	fileValues.Store(nil)
+}
+
+func (s *ReloadSuite) writeConfig(content string) {
+	s.Require().Nil(os.WriteFile(s.path, []byte(content), 0600))
+}
+
+func (s *ReloadSuite) newReloader() *Reloader {
+	reloader, err := NewReloader(s.path, func(c NodeConfig) { s.applied <- c })
+	s.Require().Nil(err)
+	return reloader
+}
+
+func (s *ReloadSuite) TestItAppliesReloadableChanges() {
+	reloader := s.newReloader()
+	s.writeConfig(`log-level = "debug"` + "\n" + `namespace = 1`)
+	s.Require().Nil(reloader.Reload())
+	s.Require().Len(s.applied, 1)
+	s.Equal(slog.LevelDebug, (<-s.applied).LogLevel)
+	s.NotContains(s.logs.String(), "level=WARN")
+
+	// nothing changed since the last reload
+	s.Require().Nil(reloader.Reload())
+	s.Empty(s.applied)
+}
+
+func (s *ReloadSuite) TestItIgnoresChangesThatNeedARestart() {
+	reloader := s.newReloader()
+	s.writeConfig(`log-level = "info"` + "\n" + `namespace = 2`)
+	s.Require().Nil(reloader.Reload())
+	s.Empty(s.applied)
+	s.Contains(s.logs.String(), "needs a restart")
+	s.Contains(s.logs.String(), "variable=ESPRESSO_NAMESPACE")
+
+	// the ignored change doesn't hold back the reloadable ones
+	s.writeConfig(`log-level = "warn"` + "\n" + `namespace = 2`)
+	s.Require().Nil(reloader.Reload())
+	s.Require().Len(s.applied, 1)
+	s.Equal(slog.LevelWarn, (<-s.applied).LogLevel)
+}
+
+func (s *ReloadSuite) TestItKeepsTheConfigWhenTheNewOneIsInvalid() {
+	reloader := s.newReloader()
+	s.writeConfig(`log-level = "loud"` + "\n" + `namespace = 1`)
+	s.ErrorContains(reloader.Reload(), "CARTESI_LOG_LEVEL")
+	s.Empty(s.applied)
+	s.Equal(slog.LevelInfo, GetLogLevel())
+
+	s.writeConfig(`log-levl = "debug"`)
+	s.ErrorContains(reloader.Reload(), "unknown key 'log-levl'")
+	s.Equal(slog.LevelInfo, GetLogLevel())
+}
+
+func (s *ReloadSuite) TestItReloadsOnSignalsAndFileChanges() {
+	reloader := s.newReloader()
+	signals := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- reloader.Run(ctx, signals) }()
+	defer func() {
+		cancel()
+		s.Nil(<-done)
+	}()
+
+	s.T().Setenv("CARTESI_LOG_LEVEL", "error")
+	signals <- syscall.SIGHUP
+	select {
+	case c := <-s.applied:
+		s.Equal(slog.LevelError, c.LogLevel)
+	case <-time.After(5 * time.Second):
+		s.FailNow("the config wasn't reloaded on SIGHUP")
+	}
+
+	os.Unsetenv("CARTESI_LOG_LEVEL")
+	s.writeConfig(`log-level = "debug"` + "\n" + `namespace = 1`)
+	select {
+	case c := <-s.applied:
+		s.Equal(slog.LevelDebug, c.LogLevel)
+	case <-time.After(5 * time.Second):
+		s.FailNow("the config wasn't reloaded when the file changed")
+	}
+}
+
+func (s *ReloadSuite) TestItReloadsWhileTheConfigIsRead() {
+	reloader := s.newReloader()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			GetLogLevel()
+			GetNamespace()
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	levels := []string{"debug", "info"}
+	for i := 0; i < 100; i++ {
+		s.writeConfig(`log-level = "` + levels[i%2] + `"` + "\n" + `namespace = 1`)
+		s.Require().Nil(reloader.Reload())
+		s.Require().Len(s.applied, 1)
+		<-s.applied
+	}
+}
@@ -0,0 +1,37 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Permissions of a secret file that are too open: any access by the group or others.
+const secretFileOpenPerm os.FileMode = 0o077
+
+// paths of the secret files already warned about, so each is warned about once
+var openSecretFiles sync.Map
+
+// readSecretFile returns the trimmed contents of the secret file at path,
+// warning if other users can access it.
+func readSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm()&secretFileOpenPerm != 0 {
+		if _, warned := openSecretFiles.LoadOrStore(path, true); !warned {
+			slog.Warn("config: secret file can be accessed by other users, restrict it with chmod 600",
+				"path", path, "mode", info.Mode().Perm())
+		}
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
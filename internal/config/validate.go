@@ -0,0 +1,192 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidationError lists all the problems found in the config.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Error()
+	}
+	return fmt.Sprintf("invalid config: %s", strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// validation collects the problems of a config.
+type validation struct {
+	problems []error
+}
+
+func (v *validation) check(ok bool, name string, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validation) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// The variables each auth kind requires.
+var authVariables = map[AuthKind][]string{
+	AuthKindPrivateKeyVar:  {"CARTESI_AUTH_PRIVATE_KEY"},
+	AuthKindPrivateKeyFile: {"CARTESI_AUTH_PRIVATE_KEY_FILE"},
+	AuthKindMnemonicVar:    {"CARTESI_AUTH_MNEMONIC"},
+	AuthKindMnemonicFile:   {"CARTESI_AUTH_MNEMONIC_FILE"},
+	AuthKindAWS:            {"CARTESI_AUTH_AWS_KMS_KEY_ID", "CARTESI_AUTH_AWS_KMS_REGION"},
+}
+
+// Load loads the config like FromEnv, but reports the missing and malformed variables,
+// and then the problems found by Validate, all at once instead of panicking.
+func Load() (NodeConfig, error) {
+	var v validation
+	for _, variable := range Variables {
+		s, _, err := Lookup(variable.Name)
+		if errors.Is(err, ErrUnset) && strings.HasPrefix(variable.Name, "CARTESI_AUTH_") {
+			// the auth variables are only required by some auth kinds
+			continue
+		}
+		if err != nil {
+			v.problems = append(v.problems, err)
+			continue
+		}
+		if err := variable.parse(s); err != nil && variable.Redact {
+			v.check(false, variable.Name, "is malformed")
+		} else if err != nil {
+			v.check(false, variable.Name, "%v", err)
+		}
+	}
+	if err := v.err(); err != nil {
+		return NodeConfig{}, err
+	}
+	if GetFeatureClaimSubmissionEnabled() {
+		kind, _, _ := Lookup("CARTESI_AUTH_KIND")
+		for _, name := range authVariables[GetAuthKind()] {
+			value, _, err := Lookup(name)
+			v.check(err == nil, name, "must be set when CARTESI_AUTH_KIND is %s", kind)
+			if err == nil && strings.HasSuffix(name, "_FILE") {
+				_, err := os.Stat(value)
+				v.check(err == nil, name, "%v", err)
+			}
+		}
+		if err := v.err(); err != nil {
+			return NodeConfig{}, err
+		}
+	}
+
+	config := FromEnv()
+	return config, config.Validate()
+}
+
+// Validate checks the values of the config, returning a ValidationError
+// with all the problems it finds.
+func (c NodeConfig) Validate() error {
+	var v validation
+
+	// values of secrets are left out of the problems
+	v.checkURL("CARTESI_BLOCKCHAIN_HTTP_ENDPOINT", c.BlockchainHttpEndpoint.Value, false, "http", "https")
+	v.checkURL("CARTESI_BLOCKCHAIN_WS_ENDPOINT", c.BlockchainWsEndpoint.Value, true, "ws", "wss")
+	v.checkURL("ESPRESSO_BASE_URL", strings.TrimSuffix(c.EspressoBaseUrl, "/v0"), false, "http", "https")
+	if endpoint := c.PostgresEndpoint.Value; strings.Contains(endpoint, "://") {
+		// the host may be left to the driver, unlike in the other URLs
+		u, err := url.Parse(endpoint)
+		v.check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"),
+			"CARTESI_POSTGRES_ENDPOINT", "must be a URL with the postgres or postgresql scheme")
+	}
+
+	v.check(c.BlockchainID > 0, "CARTESI_BLOCKCHAIN_ID", "must be greater than 0")
+	v.check(c.BlockchainBlockTimeout > 0, "CARTESI_BLOCKCHAIN_BLOCK_TIMEOUT", "must be greater than 0")
+	v.check(c.BlockchainPollingInterval > 0, "CARTESI_BLOCKCHAIN_POLLING_INTERVAL", "must be greater than 0")
+	v.check(c.EspressoNoncePendingTimeout > 0, "ESPRESSO_NONCE_PENDING_TIMEOUT", "must be greater than 0")
+	v.check(common.IsHexAddress(c.ContractsInputBoxAddress), "CARTESI_CONTRACTS_INPUT_BOX_ADDRESS",
+		"must be an address, got '%s'", c.ContractsInputBoxAddress)
+	v.check(c.ContractsInputBoxDeploymentBlockNumber >= 0,
+		"CARTESI_CONTRACTS_INPUT_BOX_DEPLOYMENT_BLOCK_NUMBER", "must not be negative")
+	v.check(c.HttpPort > 0 && c.HttpPort <= 65535, "CARTESI_HTTP_PORT",
+		"must be between 1 and 65535, got %d", c.HttpPort)
+	if mnemonic, ok := c.Auth.(AuthMnemonic); ok {
+		v.check(mnemonic.AccountIndex.Value >= 0, "CARTESI_AUTH_MNEMONIC_ACCOUNT_INDEX", "must not be negative")
+	}
+
+	v.check(slices.Contains([]string{"text", "json"}, c.LogFormat), "CARTESI_LOG_FORMAT",
+		"must be text or json, got '%s'", c.LogFormat)
+	v.check(c.LogFile == "" || c.LogFileMaxSize > 0, "CARTESI_LOG_FILE_MAX_SIZE",
+		"must be greater than 0 when CARTESI_LOG_FILE is set")
+	v.check(slices.Contains([]string{"none", "otlp", "stdout"}, c.EspressoTracingExporter),
+		"ESPRESSO_TRACING_EXPORTER", "must be none, otlp or stdout, got '%s'", c.EspressoTracingExporter)
+
+	_, _, err := net.SplitHostPort(c.EspressoServiceEndpoint)
+	v.check(err == nil, "ESPRESSO_SERVICE_ENDPOINT",
+		"must be in the host:port format, got '%s'", c.EspressoServiceEndpoint)
+	v.check((c.EspressoServiceTlsCertFile == "") == (c.EspressoServiceTlsKeyFile == ""),
+		"ESPRESSO_SERVICE_TLS_CERT_FILE", "must be set alongside ESPRESSO_SERVICE_TLS_KEY_FILE")
+	for _, file := range []struct{ name, path string }{
+		{"ESPRESSO_SERVICE_TLS_CERT_FILE", c.EspressoServiceTlsCertFile},
+		{"ESPRESSO_SERVICE_TLS_KEY_FILE", c.EspressoServiceTlsKeyFile},
+		{"ESPRESSO_SERVICE_API_KEYS_FILE", c.EspressoServiceApiKeysFile},
+	} {
+		if file.path != "" {
+			_, err := os.Stat(file.path)
+			v.check(err == nil, file.name, "%v", err)
+		}
+	}
+
+	v.check(c.EspressoMaxPayloadSize > 0, "ESPRESSO_MAX_PAYLOAD_SIZE", "must be greater than 0")
+	v.check(c.EspressoServiceMaxRequestSize > 0 &&
+		uint64(c.EspressoServiceMaxRequestSize) > 2*c.EspressoMaxPayloadSize,
+		"ESPRESSO_SERVICE_MAX_REQUEST_SIZE", "must be more than twice ESPRESSO_MAX_PAYLOAD_SIZE, got %d",
+		c.EspressoServiceMaxRequestSize)
+	for _, duration := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"CARTESI_EVM_READER_RETRY_POLICY_MAX_DELAY", c.EvmReaderRetryPolicyMaxDelay},
+		{"CARTESI_ADVANCER_POLLING_INTERVAL", c.AdvancerPollingInterval},
+		{"CARTESI_VALIDATOR_POLLING_INTERVAL", c.ValidatorPollingInterval},
+		{"CARTESI_CLAIMER_POLLING_INTERVAL", c.ClaimerPollingInterval},
+		{"ESPRESSO_SERVICE_READ_TIMEOUT", c.EspressoServiceReadTimeout},
+		{"ESPRESSO_SERVICE_WRITE_TIMEOUT", c.EspressoServiceWriteTimeout},
+		{"ESPRESSO_SERVICE_SHUTDOWN_TIMEOUT", c.EspressoServiceShutdownTimeout},
+		{"ESPRESSO_SERVICE_AUTH_MAX_CLOCK_SKEW", c.EspressoServiceAuthMaxClockSkew},
+	} {
+		v.check(duration.value >= 0, duration.name, "must not be negative")
+	}
+
+	return v.err()
+}
+
+// checkURL checks that value is a URL with one of schemes.
+// Optional URLs may be empty.
+func (v *validation) checkURL(name string, value string, optional bool, schemes ...string) {
+	if value == "" {
+		v.check(optional, name, "must be set")
+		return
+	}
+	u, err := url.Parse(value)
+	v.check(err == nil && slices.Contains(schemes, u.Scheme) && u.Host != "", name,
+		"must be a URL with the %s scheme", strings.Join(schemes, " or "))
+}
@@ -9,65 +9,18 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
 	"github.com/ZzzzHui/espresso-reader/internal/repository"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v5"
 )
 
-func SetupEspressoDB(
-	ctx context.Context,
-	database *repository.Database) error {
-	query := `CREATE TABLE IF NOT EXISTS "espresso_nonce"
-(
-    "sender_address" BYTEA NOT NULL,
-    "application_address" BYTEA NOT NULL,
-    "nonce" BIGINT NOT NULL,
-    UNIQUE("sender_address", "application_address")
-);`
-	_, err := database.GetDB().Exec(ctx, query)
-	if err != nil {
-		slog.Error("failed to create table espresso_nonce")
-		return err
-	}
-
-	query = `CREATE TABLE IF NOT EXISTS "espresso_block"
-(
-    "application_address" BYTEA PRIMARY KEY,
-	"last_processed_espresso_block" NUMERIC(20,0) NOT NULL CHECK ("last_processed_espresso_block" >= 0 AND "last_processed_espresso_block" <= f_maxuint64())
-);`
-	_, err = database.GetDB().Exec(ctx, query)
-	if err != nil {
-		slog.Error("failed to create table espresso_block")
-		return err
-	}
-
-	query = `CREATE TABLE IF NOT EXISTS "input_index"
-(
-    "application_address" BYTEA PRIMARY KEY,
-	"index" BIGINT NOT NULL
-);`
-	_, err = database.GetDB().Exec(ctx, query)
-	if err != nil {
-		slog.Error("failed to create table input_index")
-		return err
-	}
-
-	query = `ALTER TABLE input
-	ADD COLUMN IF NOT EXISTS transaction_id BYTEA;`
-	_, err = database.GetDB().Exec(ctx, query)
-	if err != nil {
-		slog.Error("failed to add column transaction_id to table input")
-		return err
-	}
-
-	return nil
-}
-
 func GetLastProcessedEspressoBlock(
 	ctx context.Context,
 	database *repository.Database,
 	application_address common.Address) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "GetLastProcessedEspressoBlock")
 
 	var lastProcessedEspressoBlock uint64
 
@@ -104,6 +57,7 @@ func UpdateLastProcessedEspressoBlock(
 	application_address common.Address,
 	last_processed_espresso_block uint64,
 ) error {
+	ctx = metrics.WithMethod(ctx, "UpdateLastProcessedEspressoBlock")
 
 	query := `
 	INSERT INTO espresso_block
@@ -16,16 +16,19 @@ import (
 	"net/http"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
 	"github.com/ZzzzHui/espresso-reader/internal/model"
 	"github.com/ZzzzHui/espresso-reader/internal/repository"
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
 
 	"github.com/EspressoSystems/espresso-sequencer-go/client"
-	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type EspressoReader struct {
@@ -37,22 +40,17 @@ type EspressoReader struct {
 	evmReader               *evmreader.EvmReader
 	chainId                 uint64
 	inputBoxDeploymentBlock uint64
+	ingestionLimits         IngestionLimits
 }
 
-func NewEspressoReader(url string, startingBlock uint64, namespace uint64, repository *repository.Database, evmReader *evmreader.EvmReader, chainId uint64, inputBoxDeploymentBlock uint64) EspressoReader {
+func NewEspressoReader(url string, startingBlock uint64, namespace uint64, repository *repository.Database, evmReader *evmreader.EvmReader, chainId uint64, inputBoxDeploymentBlock uint64, ingestionLimits IngestionLimits) EspressoReader {
 	client := client.NewClient(url)
-	return EspressoReader{url: url, client: *client, startingBlock: startingBlock, namespace: namespace, repository: repository, evmReader: evmReader, chainId: chainId, inputBoxDeploymentBlock: inputBoxDeploymentBlock}
+	return EspressoReader{url: url, client: *client, startingBlock: startingBlock, namespace: namespace, repository: repository, evmReader: evmReader, chainId: chainId, inputBoxDeploymentBlock: inputBoxDeploymentBlock, ingestionLimits: ingestionLimits}
 }
 
+// Run reads the applications until ctx is done, signaling ready once it went
+// through all of them for the first time
 func (e *EspressoReader) Run(ctx context.Context, ready chan<- struct{}) error {
-	ready <- struct{}{}
-
-	err := SetupEspressoDB(ctx, e.repository)
-	if err != nil {
-		slog.Error("failed to setup espresso db")
-		return err
-	}
-
 	for {
 		select {
 		case <-ctx.Done():
@@ -60,12 +58,15 @@ func (e *EspressoReader) Run(ctx context.Context, ready chan<- struct{}) error {
 			return ctx.Err()
 		default:
 			// fetch latest espresso block height
+			start := time.Now()
 			latestBlockHeight, err := e.client.FetchLatestBlockHeight(ctx)
+			metrics.ObserveRpc("Espresso::FetchLatestBlockHeight", start)
 			if err != nil {
 				slog.Error("failed fetching latest espresso block height", "error", err)
 				continue
 			}
-			slog.Debug("Espresso:", "latestBlockHeight", latestBlockHeight)
+			metrics.EspressoLatestHeight.Set(float64(latestBlockHeight))
+			slog.Debug("Espresso:", "espresso_height", latestBlockHeight)
 
 			apps := e.getAppsForEvmReader(ctx)
 			if len(apps) > 0 {
@@ -90,7 +91,7 @@ func (e *EspressoReader) Run(ctx context.Context, ready chan<- struct{}) error {
 							}
 						}
 						// bootstrap
-						slog.Debug("bootstrapping:", "app", appAddress, "from-block", lastProcessedEspressoBlock+1, "to-block", latestBlockHeight)
+						slog.Debug("bootstrapping:", "app", appAddress, "from_espresso_height", lastProcessedEspressoBlock+1, "espresso_height", latestBlockHeight)
 						err = e.bootstrap(ctx, app, lastProcessedEspressoBlock, latestBlockHeight, lastProcessedL1Block)
 						if err != nil {
 							slog.Error("failed reading inputs", "error", err)
@@ -101,12 +102,14 @@ func (e *EspressoReader) Run(ctx context.Context, ready chan<- struct{}) error {
 						err = UpdateLastProcessedEspressoBlock(ctx, e.repository, app.Application.ContractAddress, latestBlockHeight)
 						if err != nil {
 							slog.Error("failed updating last processed espresso block", "error", err)
+						} else {
+							metrics.EspressoProcessedHeight.WithLabelValues(appAddress.Hex()).Set(float64(latestBlockHeight))
 						}
 					} else {
 						// in sync. Process espresso blocks one-by-one
 						currentBlockHeight := lastProcessedEspressoBlock + 1
 						for ; currentBlockHeight <= latestBlockHeight; currentBlockHeight++ {
-							slog.Debug("Espresso:", "app", appAddress, "currentBlockHeight", currentBlockHeight)
+							slog.Debug("Espresso:", "app", appAddress, "espresso_height", currentBlockHeight)
 							//** read base layer **//
 							var l1FinalizedTimestamp uint64
 							lastProcessedL1Block, l1FinalizedTimestamp = e.readL1(ctx, app, currentBlockHeight, lastProcessedL1Block)
@@ -117,6 +120,8 @@ func (e *EspressoReader) Run(ctx context.Context, ready chan<- struct{}) error {
 							err = UpdateLastProcessedEspressoBlock(ctx, e.repository, app.Application.ContractAddress, latestBlockHeight)
 							if err != nil {
 								slog.Error("failed updating last processed espresso block", "error", err)
+							} else {
+								metrics.EspressoProcessedHeight.WithLabelValues(appAddress.Hex()).Set(float64(latestBlockHeight))
 							}
 						}
 					}
@@ -124,6 +129,11 @@ func (e *EspressoReader) Run(ctx context.Context, ready chan<- struct{}) error {
 				}
 			}
 
+			if ready != nil {
+				ready <- struct{}{}
+				ready = nil
+			}
+
 			// take a break :)
 			var delay time.Duration = 1000
 			time.Sleep(delay * time.Millisecond)
@@ -162,7 +172,7 @@ func (e *EspressoReader) bootstrap(ctx context.Context, app evmreader.TypeExport
 					ns := e.extractNS(nsTableBytes)
 					if slices.Contains(ns, uint32(e.namespace)) {
 						currentEspressoBlock := batchStartingBlock + uint64(index)
-						slog.Debug("found namespace contained in", "block", currentEspressoBlock)
+						slog.Debug("found namespace contained in", "espresso_height", currentEspressoBlock)
 						l1FinalizedHeight, l1FinalizedTimestamp = e.readL1(ctx, app, currentEspressoBlock, l1FinalizedHeight)
 						e.readEspresso(ctx, app, currentEspressoBlock, l1FinalizedHeight, l1FinalizedTimestamp)
 					}
@@ -177,9 +187,10 @@ func (e *EspressoReader) bootstrap(ctx context.Context, app evmreader.TypeExport
 
 func (e *EspressoReader) readL1(ctx context.Context, app evmreader.TypeExportApplication, currentBlockHeight uint64, lastProcessedL1Block uint64) (uint64, uint64) {
 	l1FinalizedLatestHeight, l1FinalizedTimestamp := e.getL1FinalizedHeight(ctx, currentBlockHeight)
+	metrics.L1FinalizedBlock.Set(float64(l1FinalizedLatestHeight))
 	// read L1 if there might be update
 	if l1FinalizedLatestHeight > lastProcessedL1Block {
-		slog.Debug("L1 finalized", "app", app.Application.ContractAddress, "from", lastProcessedL1Block, "to", l1FinalizedLatestHeight)
+		slog.Debug("L1 finalized", "app", app.Application.ContractAddress, "from_l1_block", lastProcessedL1Block, "l1_block", l1FinalizedLatestHeight)
 
 		var apps []evmreader.TypeExportApplication
 		apps = append(apps, app) // make app into 1-element array
@@ -194,170 +205,210 @@ func (e *EspressoReader) readL1(ctx context.Context, app evmreader.TypeExportApp
 }
 
 func (e *EspressoReader) readEspresso(ctx context.Context, appEvmType evmreader.TypeExportApplication, currentBlockHeight uint64, l1FinalizedLatestHeight uint64, l1FinalizedTimestamp uint64) {
-	app := appEvmType.Application.ContractAddress
-	transactions, err := e.client.FetchTransactionsInBlock(ctx, currentBlockHeight, e.namespace)
+	fetchCtx, span := tracing.Start(ctx, "Espresso::FetchTransactionsInBlock",
+		tracing.AttrEspressoBlock.Int64(int64(currentBlockHeight)))
+	start := time.Now()
+	transactions, err := e.client.FetchTransactionsInBlock(fetchCtx, currentBlockHeight, e.namespace)
+	metrics.ObserveRpc("Espresso::FetchTransactionsInBlock", start)
+	tracing.End(span, err)
 	if err != nil {
 		slog.Error("failed fetching espresso tx", "error", err)
 		return
 	}
 
-	numTx := len(transactions.Transactions)
+	quota := newBlockQuota(e.ingestionLimits)
+	for _, transaction := range transactions.Transactions {
+		e.readEspressoTransaction(ctx, appEvmType, string(transaction), currentBlockHeight, l1FinalizedLatestHeight, l1FinalizedTimestamp, quota)
+	}
+}
 
-	for i := 0; i < numTx; i++ {
-		transaction := transactions.Transactions[i]
+// readEspressoTransaction stores the input of an Espresso transaction, if it is a valid one for the application
+func (e *EspressoReader) readEspressoTransaction(ctx context.Context, appEvmType evmreader.TypeExportApplication, transaction string, currentBlockHeight uint64, l1FinalizedLatestHeight uint64, l1FinalizedTimestamp uint64, quota *blockQuota) {
+	app := appEvmType.Application.ContractAddress
+	ctx, span := tracing.Start(ctx, "EspressoReader::ReadTransaction",
+		tracing.AttrApp.String(app.Hex()),
+		tracing.AttrEspressoBlock.Int64(int64(currentBlockHeight)))
+	defer span.End()
 
-		msgSender, typedData, sigHash, err := ExtractSigAndData(string(transaction))
-		if err != nil {
-			slog.Error("failed to extract espresso tx", "error", err)
-			continue
-		}
+	msgSender, typedData, sigHash, err := extractSigAndData(ctx, transaction)
+	if err != nil {
+		slog.Error("failed to extract espresso tx", "error", err)
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageReader, "malformed_transaction").Inc()
+		return
+	}
 
-		nonce := uint64(typedData.Message["nonce"].(float64))
-		payload := typedData.Message["data"].(string)
-		appAddressStr := typedData.Message["app"].(string)
-		appAddress := common.HexToAddress(appAddressStr)
-		if appAddress != app {
-			slog.Debug("skipping tx that doesn't belong to", "app", app)
-			continue
-		}
-		slog.Info("Espresso input", "msgSender", msgSender, "nonce", nonce, "payload", payload, "appAddrss", appAddress, "tx-id", sigHash)
+	// decode the message the same way the service validated it
+	message, err := typeddata.ParseCartesiMessage(typedData.Message)
+	if err != nil {
+		slog.Error("failed to decode espresso message", "tx_id", sigHash, "error", err)
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageReader, "malformed_transaction").Inc()
+		return
+	}
+	nonce := message.Nonce
+	appAddress := message.App
+	if appAddress != app {
+		slog.Debug("skipping tx that doesn't belong to", "app", app)
+		return
+	}
+	slog.Info("Espresso input", "msgSender", msgSender, "nonce", nonce, "payload", hexutil.Bytes(message.Data), "app", appAddress, "tx_id", sigHash)
+	span.SetAttributes(tracing.AttrTxId.String(sigHash))
+	tracing.DefaultSubmissions.Link(span, sigHash)
 
-		// validate nonce
-		nonceInDb, err := e.repository.GetEspressoNonce(ctx, msgSender, appAddress)
-		if err != nil {
-			slog.Error("failed to get espresso nonce from db", "error", err)
-			continue
-		}
-		if nonce != nonceInDb {
-			slog.Error("Espresso nonce is incorrect. May be a duplicate tx", "nonce from espresso", nonce, "nonce in db", nonceInDb)
-			continue
-		}
+	// validate nonce
+	nonceInDb, err := e.repository.GetEspressoNonce(ctx, msgSender, appAddress)
+	if err != nil {
+		slog.Error("failed to get espresso nonce from db", "error", err)
+		return
+	}
+	if nonce != nonceInDb {
+		slog.Error("Espresso nonce is incorrect. May be a duplicate tx", "nonce from espresso", nonce, "nonce in db", nonceInDb)
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageReader, "invalid_nonce").Inc()
+		return
+	}
+	if !quota.allow(msgSender) {
+		slog.Warn("skipping espresso input over the block quota",
+			"app", appAddress, "msgSender", msgSender, "espresso_height", currentBlockHeight, "tx_id", sigHash)
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageReader, "over_block_quota").Inc()
+		return
+	}
 
-		payloadBytes := []byte(payload)
-		if strings.HasPrefix(payload, "0x") {
-			payload = payload[2:] // remove 0x
-			payloadBytes, err = hex.DecodeString(payload)
-			if err != nil {
-				slog.Error("failed to decode hex string", "error", err)
-				continue
-			}
-		}
-		// abi encode payload
-		abiObject := e.evmReader.IOAbi
-		chainId := &big.Int{}
-		chainId.SetInt64(int64(e.chainId))
-		l1FinalizedLatestHeightBig := &big.Int{}
-		l1FinalizedLatestHeightBig.SetUint64(l1FinalizedLatestHeight)
-		l1FinalizedTimestampBig := &big.Int{}
-		l1FinalizedTimestampBig.SetUint64(l1FinalizedTimestamp)
-		prevRandao, err := readPrevRandao(ctx, l1FinalizedLatestHeight, e.evmReader.GetEthClient())
-		if err != nil {
-			slog.Error("failed to read prevrandao", "error", err)
-		}
-		index := &big.Int{}
-		indexUint64, err := e.repository.GetInputIndex(ctx, appAddress)
-		if err != nil {
-			slog.Error("failed to read index", "app", appAddress, "error", err)
-		}
-		index.SetUint64(indexUint64)
-		payloadAbi, err := abiObject.Pack("EvmAdvance", chainId, appAddress, msgSender, l1FinalizedLatestHeightBig, l1FinalizedTimestampBig, prevRandao, index, payloadBytes)
-		if err != nil {
-			slog.Error("failed to abi encode", "error", err)
-			continue
-		}
+	payloadBytes := message.Data
+	// abi encode payload
+	abiObject := e.evmReader.IOAbi
+	chainId := &big.Int{}
+	chainId.SetInt64(int64(e.chainId))
+	l1FinalizedLatestHeightBig := &big.Int{}
+	l1FinalizedLatestHeightBig.SetUint64(l1FinalizedLatestHeight)
+	l1FinalizedTimestampBig := &big.Int{}
+	l1FinalizedTimestampBig.SetUint64(l1FinalizedTimestamp)
+	prevRandao, err := readPrevRandao(ctx, l1FinalizedLatestHeight, e.evmReader.GetEthClient())
+	if err != nil {
+		slog.Error("failed to read prevrandao", "error", err)
+	}
+	index := &big.Int{}
+	indexUint64, err := e.repository.GetInputIndex(ctx, appAddress)
+	if err != nil {
+		slog.Error("failed to read index", "app", appAddress, "error", err)
+	}
+	index.SetUint64(indexUint64)
+	payloadAbi, err := abiObject.Pack("EvmAdvance", chainId, appAddress, msgSender, l1FinalizedLatestHeightBig, l1FinalizedTimestampBig, prevRandao, index, payloadBytes)
+	if err != nil {
+		slog.Error("failed to abi encode", "error", err)
+		return
+	}
 
-		// build epochInputMap
-		// Initialize epochs inputs map
-		var epochInputMap = make(map[*model.Epoch][]model.Input)
-		// get epoch length and last open epoch
-		epochLength := e.evmReader.GetEpochLengthCache(appAddress)
-		if epochLength == 0 {
-			err = e.evmReader.AddAppEpochLengthIntoCache(appEvmType)
-			epochLength = e.evmReader.GetEpochLengthCache(appAddress)
-			if err != nil || epochLength == 0 {
-				slog.Error("could not obtain epoch length")
-				continue
-			}
+	// build epochInputMap
+	// Initialize epochs inputs map
+	var epochInputMap = make(map[*model.Epoch][]model.Input)
+	// get epoch length and last open epoch
+	epochLength := e.evmReader.GetEpochLengthCache(appAddress)
+	if epochLength == 0 {
+		err = e.evmReader.AddAppEpochLengthIntoCache(appEvmType)
+		epochLength = e.evmReader.GetEpochLengthCache(appAddress)
+		if err != nil || epochLength == 0 {
+			slog.Error("could not obtain epoch length")
+			return
 		}
-		currentEpoch, err := e.repository.GetEpoch(ctx,
-			epochLength, appAddress)
-		if err != nil {
-			slog.Error("could not obtain current epoch", "err", err)
-			continue
-		}
-		// if currect epoch is not nil, assume the epoch is open
-		// espresso inputs do not close epoch
-		epochIndex := evmreader.CalculateEpochIndex(epochLength, l1FinalizedLatestHeight)
-		if currentEpoch == nil {
-			currentEpoch = &model.Epoch{
-				Index:      epochIndex,
-				FirstBlock: epochIndex * epochLength,
-				LastBlock:  (epochIndex * epochLength) + epochLength - 1,
-				Status:     model.EpochStatusOpen,
-				AppAddress: appAddress,
-			}
+	}
+	currentEpoch, err := e.repository.GetEpoch(ctx,
+		epochLength, appAddress)
+	if err != nil {
+		slog.Error("could not obtain current epoch", "err", err)
+		return
+	}
+	// if currect epoch is not nil, assume the epoch is open
+	// espresso inputs do not close epoch
+	epochIndex := evmreader.CalculateEpochIndex(epochLength, l1FinalizedLatestHeight)
+	if currentEpoch == nil {
+		currentEpoch = &model.Epoch{
+			Index:      epochIndex,
+			FirstBlock: epochIndex * epochLength,
+			LastBlock:  (epochIndex * epochLength) + epochLength - 1,
+			Status:     model.EpochStatusOpen,
+			AppAddress: appAddress,
 		}
-		// build input
-		sigHashHexBytes, err := hex.DecodeString(sigHash[2:])
+	}
+	// build input
+	sigHashHexBytes, err := hex.DecodeString(sigHash[2:])
+	if err != nil {
+		slog.Error("could not obtain bytes for tx-id", "err", err)
+		return
+	}
+	input := model.Input{
+		Index:            indexUint64,
+		CompletionStatus: model.InputStatusNone,
+		RawData:          payloadAbi,
+		BlockNumber:      l1FinalizedLatestHeight,
+		AppAddress:       appAddress,
+		TransactionId:    sigHashHexBytes,
+	}
+	currentInputs, ok := epochInputMap[currentEpoch]
+	if !ok {
+		currentInputs = []model.Input{}
+	}
+	epochInputMap[currentEpoch] = append(currentInputs, input)
+
+	// Store everything
+	// future optimization: bundle tx by address to fully utilize `epochInputMap``
+	if len(epochInputMap) > 0 {
+		_, _, err = e.repository.StoreEpochAndInputsTransaction(
+			ctx,
+			epochInputMap,
+			l1FinalizedLatestHeight,
+			appAddress,
+		)
 		if err != nil {
-			slog.Error("could not obtain bytes for tx-id", "err", err)
-			continue
-		}
-		input := model.Input{
-			Index:            indexUint64,
-			CompletionStatus: model.InputStatusNone,
-			RawData:          payloadAbi,
-			BlockNumber:      l1FinalizedLatestHeight,
-			AppAddress:       appAddress,
-			TransactionId:    sigHashHexBytes,
-		}
-		currentInputs, ok := epochInputMap[currentEpoch]
-		if !ok {
-			currentInputs = []model.Input{}
-		}
-		epochInputMap[currentEpoch] = append(currentInputs, input)
-
-		// Store everything
-		// future optimization: bundle tx by address to fully utilize `epochInputMap``
-		if len(epochInputMap) > 0 {
-			_, _, err = e.repository.StoreEpochAndInputsTransaction(
-				ctx,
-				epochInputMap,
-				l1FinalizedLatestHeight,
-				appAddress,
-			)
-			if err != nil {
-				slog.Error("could not store Espresso input", "err", err)
-				continue
-			}
+			slog.Error("could not store Espresso input", "err", err)
+			return
 		}
+		metrics.InputsIngested.WithLabelValues(appAddress.Hex(), metrics.SourceEspresso).Inc()
+	}
+	quota.consume(msgSender)
 
-		// update nonce
-		err = e.repository.UpdateEspressoNonce(ctx, msgSender, appAddress)
-		if err != nil {
-			slog.Error("!!!could not update Espresso nonce!!!", "err", err)
-			continue
-		}
-		// update input index
-		err = e.repository.UpdateInputIndex(ctx, appAddress)
-		if err != nil {
-			slog.Error("failed to update index", "app", appAddress, "error", err)
-		}
+	// update nonce
+	err = e.repository.UpdateEspressoNonce(ctx, msgSender, appAddress)
+	if err != nil {
+		slog.Error("!!!could not update Espresso nonce!!!", "err", err)
+		return
+	}
+	// update input index
+	err = e.repository.UpdateInputIndex(ctx, appAddress)
+	if err != nil {
+		slog.Error("failed to update index", "app", appAddress, "error", err)
 	}
 }
 
-func (e *EspressoReader) readEspressoHeader(espressoBlockHeight uint64) string {
+func (e *EspressoReader) readEspressoHeader(ctx context.Context, espressoBlockHeight uint64) string {
 	requestURL := fmt.Sprintf("%s/availability/header/%d", e.url, espressoBlockHeight)
-	res, err := http.Get(requestURL)
+	resBody, err := e.getEspresso(ctx, "Espresso::GetHeader", requestURL,
+		tracing.AttrEspressoBlock.Int64(int64(espressoBlockHeight)))
 	if err != nil {
-		slog.Error("error making http request", "err", err)
+		slog.Error("error fetching espresso header", "err", err)
+		return ""
 	}
+	return string(resBody)
+}
+
+// getEspresso reads the response to a GET request to the Espresso API, as the method span
+func (e *EspressoReader) getEspresso(ctx context.Context, method string, requestURL string, attrs ...attribute.KeyValue) (_ []byte, err error) {
+	ctx, span := tracing.Start(ctx, method, attrs...)
+	defer func() { tracing.End(span, err) }()
+	defer metrics.ObserveRpc(method, time.Now())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making http request: %w", err)
+	}
+	defer res.Body.Close()
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		slog.Error("could not read response body", "err", err)
+		return nil, fmt.Errorf("could not read response body: %w", err)
 	}
-
-	return string(resBody)
+	return resBody, nil
 }
 
 func (e *EspressoReader) getL1FinalizedHeight(ctx context.Context, espressoBlockHeight uint64) (uint64, uint64) {
@@ -367,9 +418,9 @@ func (e *EspressoReader) getL1FinalizedHeight(ctx context.Context, espressoBlock
 			slog.Info("exiting espresso reader")
 			return 0, 0
 		default:
-			espressoHeader := e.readEspressoHeader(espressoBlockHeight)
+			espressoHeader := e.readEspressoHeader(ctx, espressoBlockHeight)
 			if len(espressoHeader) == 0 {
-				slog.Error("error fetching espresso header", "at height", espressoBlockHeight, "header", espressoHeader)
+				slog.Error("error fetching espresso header", "espresso_height", espressoBlockHeight, "header", espressoHeader)
 				slog.Error("retrying fetching header")
 				continue
 			}
@@ -377,7 +428,7 @@ func (e *EspressoReader) getL1FinalizedHeight(ctx context.Context, espressoBlock
 			l1FinalizedNumber := gjson.Get(espressoHeader, "fields.l1_finalized.number").Uint()
 			l1FinalizedTimestampStr := gjson.Get(espressoHeader, "fields.l1_finalized.timestamp").Str
 			if len(l1FinalizedTimestampStr) < 2 {
-				slog.Debug("Espresso header not ready. Retry fetching", "height", espressoBlockHeight)
+				slog.Debug("Espresso header not ready. Retry fetching", "espresso_height", espressoBlockHeight)
 				var delay time.Duration = 3000
 				time.Sleep(delay * time.Millisecond)
 				continue
@@ -402,15 +453,10 @@ func (e *EspressoReader) readEspressoHeadersByRange(ctx context.Context, from ui
 			return ""
 		default:
 			requestURL := fmt.Sprintf("%s/availability/header/%d/%d", e.url, from, until)
-			res, err := http.Get(requestURL)
-			if err != nil {
-				slog.Error("error making http request", "err", err)
-				slog.Error("retrying")
-				continue
-			}
-			resBody, err := io.ReadAll(res.Body)
+			resBody, err := e.getEspresso(ctx, "Espresso::GetHeaderRange", requestURL,
+				tracing.AttrFromBlock.Int64(int64(from)), tracing.AttrToBlock.Int64(int64(until)))
 			if err != nil {
-				slog.Error("could not read response body", "err", err)
+				slog.Error("error fetching espresso headers", "err", err)
 				slog.Error("retrying")
 				continue
 			}
@@ -484,11 +530,14 @@ func (e *EspressoReader) getAppsForEvmReader(ctx context.Context) []evmreader.Ty
 }
 
 func readPrevRandao(ctx context.Context, l1FinalizedLatestHeight uint64, client *evmreader.EthClient) (*big.Int, error) {
+	ctx, span := tracing.Start(ctx, "EspressoReader::ReadPrevRandao",
+		tracing.AttrL1Block.Int64(int64(l1FinalizedLatestHeight)))
 	header, err := (*client).HeaderByNumber(ctx, big.NewInt(int64(l1FinalizedLatestHeight)))
+	tracing.End(span, err)
 	if err != nil {
 		return &big.Int{}, fmt.Errorf("espresso read block header error: %w", err)
 	}
 	prevRandao := header.MixDigest.Big()
-	slog.Debug("readPrevRandao", "prevRandao", prevRandao, "blockNumber", l1FinalizedLatestHeight)
+	slog.Debug("readPrevRandao", "prevRandao", prevRandao, "l1_block", l1FinalizedLatestHeight)
 	return prevRandao, nil
 }
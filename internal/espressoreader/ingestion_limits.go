@@ -0,0 +1,45 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoreader
+
+import "github.com/ethereum/go-ethereum/common"
+
+// IngestionLimits caps how many inputs the reader takes for an application
+// from a single Espresso block. Zero means no limit.
+//
+// Inputs over the limits are skipped without consuming their nonces,
+// so every reader of an application must use the same limits.
+type IngestionLimits struct {
+	MaxInputsPerBlock          uint64
+	MaxInputsPerSenderPerBlock uint64
+}
+
+// blockQuota keeps track of the inputs taken from a single block
+type blockQuota struct {
+	limits  IngestionLimits
+	inputs  uint64
+	senders map[common.Address]uint64
+}
+
+func newBlockQuota(limits IngestionLimits) *blockQuota {
+	return &blockQuota{limits: limits, senders: make(map[common.Address]uint64)}
+}
+
+// allow reports whether another input from the sender fits in the block
+func (q *blockQuota) allow(sender common.Address) bool {
+	if q.limits.MaxInputsPerBlock > 0 && q.inputs >= q.limits.MaxInputsPerBlock {
+		return false
+	}
+	if q.limits.MaxInputsPerSenderPerBlock > 0 &&
+		q.senders[sender] >= q.limits.MaxInputsPerSenderPerBlock {
+		return false
+	}
+	return true
+}
+
+// consume records an input taken from the sender
+func (q *blockQuota) consume(sender common.Address) {
+	q.inputs++
+	q.senders[sender]++
+}
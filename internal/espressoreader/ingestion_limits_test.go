@@ -0,0 +1,57 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoreader
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	quotaTestSender      = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	quotaTestOtherSender = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+)
+
+type BlockQuotaSuite struct {
+	suite.Suite
+}
+
+func TestBlockQuotaSuite(t *testing.T) {
+	suite.Run(t, new(BlockQuotaSuite))
+}
+
+func (s *BlockQuotaSuite) TestItIsUnlimitedByDefault() {
+	quota := newBlockQuota(IngestionLimits{})
+	for i := 0; i < 1000; i++ {
+		s.True(quota.allow(quotaTestSender))
+		quota.consume(quotaTestSender)
+	}
+}
+
+func (s *BlockQuotaSuite) TestItCapsInputsPerSender() {
+	quota := newBlockQuota(IngestionLimits{MaxInputsPerSenderPerBlock: 2})
+	for i := 0; i < 2; i++ {
+		s.True(quota.allow(quotaTestSender))
+		quota.consume(quotaTestSender)
+	}
+	s.False(quota.allow(quotaTestSender))
+	s.True(quota.allow(quotaTestOtherSender))
+}
+
+func (s *BlockQuotaSuite) TestItCapsInputsPerBlock() {
+	quota := newBlockQuota(IngestionLimits{MaxInputsPerBlock: 3, MaxInputsPerSenderPerBlock: 2})
+	quota.consume(quotaTestSender)
+	quota.consume(quotaTestSender)
+	quota.consume(quotaTestOtherSender)
+	s.False(quota.allow(quotaTestOtherSender))
+}
+
+func (s *BlockQuotaSuite) TestItOnlyCountsConsumedInputs() {
+	quota := newBlockQuota(IngestionLimits{MaxInputsPerBlock: 1})
+	// inputs that were allowed but failed to be stored do not count
+	s.True(quota.allow(quotaTestSender))
+	s.True(quota.allow(quotaTestSender))
+}
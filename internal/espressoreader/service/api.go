@@ -0,0 +1,464 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+//go:generate go run github.com/deepmap/oapi-codegen/v2/cmd/oapi-codegen -config=oapi.yaml ../../../api/openapi/espresso.yaml
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader"
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
+
+	"github.com/EspressoSystems/espresso-sequencer-go/client"
+	"github.com/EspressoSystems/espresso-sequencer-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// make sure the service implements every endpoint of api/openapi/espresso.yaml
+var _ ServerInterface = (*EspressoReaderService)(nil)
+
+// NewHandler returns the HTTP handler of the Espresso service API
+func NewHandler(si ServerInterface, config HttpServerConfig) http.Handler {
+	router := chi.NewRouter()
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound,
+			fmt.Sprintf("no such endpoint: %s", r.URL.Path))
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed on %s", r.Method, r.URL.Path))
+	})
+	authenticator := newAuthenticator(config.Auth)
+	// not part of the API, but it tells how the node runs, so it needs admin credentials
+	router.With(requireScopes(ScopeAdmin), authenticator.middleware).
+		Method(http.MethodGet, "/metrics", metrics.Handler())
+	if service, ok := si.(probes); ok {
+		router.Get("/healthz", service.healthz)
+		router.Get("/readyz", service.readyz)
+	}
+	handler := HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: router,
+		// the last middleware runs first
+		Middlewares: []MiddlewareFunc{
+			authenticator.middleware,
+			limitRequestSize(config.MaxRequestSize),
+			countRequests,
+		},
+		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			writeError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		},
+	})
+	return allowCORS(config.CorsAllowedOrigins, forwardedFor(config.TrustedProxies, handler))
+}
+
+// allowCORS lets browser wallets call the service from the allowed origins
+func allowCORS(allowedOrigins []string, next http.Handler) http.Handler {
+	allowAny := slices.Contains(allowedOrigins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if allowAny {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else if origin != "" && slices.Contains(allowedOrigins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers, "+
+				strings.Join([]string{HeaderApiKey, HeaderApiKeyId, HeaderTimestamp, HeaderSignature}, ", "))
+		}
+		// preflight requests from origins not allowed get no CORS headers,
+		// so the browser blocks the actual request
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// countRequests counts the requests to each endpoint by status code
+func countRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		path := r.URL.Path
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+			path = routeContext.RoutePattern()
+		}
+		metrics.ServiceRequests.WithLabelValues(path, strconv.Itoa(ww.Status())).Inc()
+	})
+}
+
+// limitRequestSize rejects request bodies larger than maxSize bytes
+func limitRequestSize(maxSize int64) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxSize {
+				writeRequestTooLarge(w, maxSize)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeRequestTooLarge(w http.ResponseWriter, maxSize int64) {
+	writeError(w, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge,
+		fmt.Sprintf("request body is larger than %d bytes", maxSize))
+}
+
+// writeReadError responds to a failure to read the request body
+func writeReadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeRequestTooLarge(w, maxBytesErr.Limit)
+		return
+	}
+	writeError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("could not read body: %v", err))
+}
+
+// writeRateLimited responds with 429, telling the client when to retry
+func writeRateLimited(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	writeError(w, http.StatusTooManyRequests, ErrCodeRateLimited,
+		fmt.Sprintf("%s, retry in %d seconds", message, seconds))
+}
+
+// ParseTrustedProxies parses a list of IP addresses and CIDR ranges
+func ParseTrustedProxies(items []string) ([]netip.Prefix, error) {
+	proxies := make([]netip.Prefix, len(items))
+	for i, item := range items {
+		if addr, err := netip.ParseAddr(item); err == nil {
+			proxies[i] = netip.PrefixFrom(addr, addr.BitLen())
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy '%s': must be an IP address or a CIDR range", item)
+		}
+		proxies[i] = prefix.Masked()
+	}
+	return proxies, nil
+}
+
+type clientIpKey struct{}
+
+// forwardedFor attributes the requests from trusted proxies to the client in their X-Forwarded-For header.
+// Proxies append the address they got the request from, so the header is read from the right,
+// skipping the trusted proxies, as the entries left of the first untrusted one may be forged by the client.
+func forwardedFor(trustedProxies []netip.Prefix, next http.Handler) http.Handler {
+	trusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		return slices.ContainsFunc(trustedProxies, func(proxy netip.Prefix) bool {
+			return proxy.Contains(addr)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIp(r)
+		if trusted(ip) {
+			var hops []string
+			for _, header := range r.Header.Values("X-Forwarded-For") {
+				hops = append(hops, strings.Split(header, ",")...)
+			}
+			for i := len(hops) - 1; i >= 0; i-- {
+				hop := strings.TrimSpace(hops[i])
+				if _, err := netip.ParseAddr(hop); err != nil {
+					break
+				}
+				ip = hop
+				if !trusted(hop) {
+					break
+				}
+			}
+			r = r.WithContext(context.WithValue(r.Context(), clientIpKey{}, ip))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIp returns the IP address of the client of the service,
+// which is the one connected to it unless it is a trusted proxy
+func clientIp(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIpKey{}).(string); ok {
+		return ip
+	}
+	return remoteIp(r)
+}
+
+// remoteIp returns the IP address connected to the service
+func remoteIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		slog.Error("espresso service: failed to write response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, ErrorResponse{Code: code, Message: message})
+}
+
+// RequestNonce implements POST /nonce
+func (s *EspressoReaderService) RequestNonce(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+	var nonceRequest NonceRequest
+	if err := json.Unmarshal(body, &nonceRequest); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("invalid nonce request: %v", err))
+		return
+	}
+	if !common.IsHexAddress(nonceRequest.MsgSender) || !common.IsHexAddress(nonceRequest.AppContract) {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest, "msg_sender and app_contract must be addresses")
+		return
+	}
+
+	senderAddress := common.HexToAddress(nonceRequest.MsgSender)
+	applicationAddress := common.HexToAddress(nonceRequest.AppContract)
+	slog.Debug("got nonce request", "senderAddress", senderAddress, "applicationAddress", applicationAddress)
+
+	nonce, err := s.nonceManager.Next(r.Context(), applicationAddress, senderAddress)
+	if err != nil {
+		slog.Error("failed to get espresso nonce", "error", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get nonce")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NonceResponse{Nonce: nonce})
+}
+
+// SubmitTransaction implements POST /submit
+func (s *EspressoReaderService) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.StartRequest(r, "Service::SubmitTransaction")
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	if ok, retryAfter := s.rateLimiter.AllowIp(clientIp(r)); !ok {
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageService, ErrCodeRateLimited).Inc()
+		writeRateLimited(w, retryAfter, "too many submissions from this address")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+	slog.Debug("got submit request", "request body", string(body))
+
+	submission, err := s.submissionValidator.Validate(ctx, body)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			slog.Info("rejected espresso submission", "reason", validationErr)
+			metrics.TransactionsRejected.WithLabelValues(metrics.StageService, validationErr.Code).Inc()
+			writeError(w, validationErr.Status, validationErr.Code, validationErr.Message)
+			return
+		}
+		slog.Error("failed to validate espresso submission", "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to validate submission")
+		return
+	}
+
+	span.SetAttributes(
+		tracing.AttrApp.String(submission.Message.App.Hex()),
+		tracing.AttrTxId.String(submission.TxId))
+
+	if ok, retryAfter := s.rateLimiter.AllowSubmission(submission.Sender, submission.Message.App); !ok {
+		slog.Info("throttled espresso submission",
+			"sender", submission.Sender, "app", submission.Message.App)
+		s.submissionValidator.Release(submission)
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageService, ErrCodeRateLimited).Inc()
+		writeRateLimited(w, retryAfter, "too many submissions from this sender or to this application")
+		return
+	}
+
+	client := client.NewClient(s.EspressoBaseUrl)
+	var tx types.Transaction
+	tx.Namespace = s.EspressoNamespace
+	tx.Payload = submission.Payload
+	submitCtx, submitSpan := tracing.Start(ctx, "Espresso::SubmitTransaction")
+	_, err = client.SubmitTransaction(submitCtx, tx)
+	tracing.End(submitSpan, err)
+	if err != nil {
+		s.submissionValidator.Release(submission)
+		slog.Error("espresso tx submit error", "err", err)
+		writeError(w, http.StatusBadGateway, ErrCodeEspressoUnavailable,
+			"failed to submit transaction to Espresso")
+		return
+	}
+
+	// so the reader links the ingestion of the transaction to this request
+	tracing.DefaultSubmissions.Record(ctx, submission.TxId)
+
+	writeJSON(w, http.StatusOK, SubmitResponse{Id: submission.TxId})
+}
+
+// GetDomain implements GET /domain
+func (s *EspressoReaderService) GetDomain(w http.ResponseWriter, r *http.Request, params GetDomainParams) {
+	if !common.IsHexAddress(params.App) {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest,
+			fmt.Sprintf("app must be an address, got '%s'", params.App))
+		return
+	}
+	appAddress := common.HexToAddress(params.App)
+
+	app, err := s.applications.GetApplication(r.Context(), appAddress)
+	if err != nil {
+		slog.Error("failed to get application", "app", appAddress, "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get application")
+		return
+	}
+	if app == nil {
+		writeError(w, http.StatusNotFound, ErrCodeApplicationNotFound,
+			fmt.Sprintf("application %s is not registered", appAddress))
+		return
+	}
+
+	types := make(map[string][]TypedDataField, len(typeddata.Types))
+	for name, fields := range typeddata.Types {
+		for _, field := range fields {
+			types[name] = append(types[name], TypedDataField{Name: field.Name, Type: field.Type})
+		}
+	}
+	writeJSON(w, http.StatusOK, DomainInfo{
+		App: appAddress.Hex(),
+		Domain: TypedDataDomain{
+			Name:              typeddata.DomainName,
+			Version:           typeddata.DomainVersion,
+			ChainId:           s.chainId,
+			VerifyingContract: common.Address{}.Hex(),
+		},
+		Types:          types,
+		PrimaryType:    typeddata.PrimaryType,
+		ChainId:        s.chainId,
+		Namespace:      s.EspressoNamespace,
+		MaxPayloadSize: s.submissionValidator.maxPayloadSize,
+	})
+}
+
+// GetTransactionStatus implements GET /transactions/{id}
+func (s *EspressoReaderService) GetTransactionStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	id TransactionId,
+) {
+	transactionId, err := hexutil.Decode(id)
+	if err != nil || len(transactionId) != common.HashLength {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest,
+			fmt.Sprintf("transaction id must be a 32-byte hex string, got '%s'", id))
+		return
+	}
+
+	input, err := s.database.GetInputByTransactionId(r.Context(), transactionId)
+	if err != nil {
+		slog.Error("failed to get input by transaction id", "id", id, "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get transaction status")
+		return
+	}
+
+	status := TransactionStatus{Id: hexutil.Encode(transactionId), Status: UNKNOWN}
+	if input != nil {
+		status.Status = INGESTED
+		status.Input = &Input{
+			AppContract: input.AppAddress.Hex(),
+			Index:       input.Index,
+			BlockNumber: input.BlockNumber,
+			Status:      string(input.CompletionStatus),
+		}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GetApplication implements GET /applications/{address}
+func (s *EspressoReaderService) GetApplication(
+	w http.ResponseWriter,
+	r *http.Request,
+	address ApplicationAddress,
+) {
+	if !common.IsHexAddress(address) {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest,
+			fmt.Sprintf("application must be an address, got '%s'", address))
+		return
+	}
+	ctx := r.Context()
+	appAddress := common.HexToAddress(address)
+
+	app, err := s.database.GetApplication(ctx, appAddress)
+	if err != nil {
+		slog.Error("failed to get application", "app", appAddress, "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get application")
+		return
+	}
+	if app == nil {
+		writeError(w, http.StatusNotFound, ErrCodeApplicationNotFound,
+			fmt.Sprintf("application %s is not registered", appAddress))
+		return
+	}
+
+	lastProcessedEspressoBlock, err := espressoreader.GetLastProcessedEspressoBlock(ctx, s.database, appAddress)
+	if err != nil {
+		slog.Error("failed to get last processed espresso block", "app", appAddress, "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get application")
+		return
+	}
+	nextInputIndex, err := s.database.GetInputIndex(ctx, appAddress)
+	if err != nil {
+		slog.Error("failed to get input index", "app", appAddress, "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get application")
+		return
+	}
+
+	status := ApplicationInfoStatusNOTRUNNING
+	if app.Status == model.ApplicationStatusRunning {
+		status = ApplicationInfoStatusRUNNING
+	}
+	writeJSON(w, http.StatusOK, ApplicationInfo{
+		ContractAddress:            app.ContractAddress.Hex(),
+		IconsensusAddress:          app.IConsensusAddress.Hex(),
+		TemplateHash:               app.TemplateHash.Hex(),
+		Status:                     status,
+		LastProcessedBlock:         app.LastProcessedBlock,
+		LastProcessedEspressoBlock: lastProcessedEspressoBlock,
+		NextInputIndex:             nextInputIndex,
+	})
+}
@@ -0,0 +1,359 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type ApiSuite struct {
+	suite.Suite
+	nonces  *FakeNonceRepository
+	service *EspressoReaderService
+	handler http.Handler
+}
+
+func TestApiSuite(t *testing.T) {
+	suite.Run(t, new(ApiSuite))
+}
+
+func (s *ApiSuite) SetupTest() {
+	s.nonces = newFakeNonceRepository()
+	nonceManager := NewNonceManager(s.nonces, time.Minute)
+	applications := newMockApplicationRepository()
+	s.service = &EspressoReaderService{
+		EspressoNamespace: 55555,
+		chainId:           validationTestChainId,
+		applications:      applications,
+		nonceManager:      nonceManager,
+		rateLimiter: NewSubmitRateLimiter(RateLimitConfig{
+			PerIp: RateLimit{PerMinute: 1, Burst: 2},
+		}),
+		submissionValidator: NewSubmissionValidator(
+			applications, nonceManager, validationTestChainId, 16),
+		logLevel: new(slog.LevelVar),
+	}
+	s.handler = NewHandler(s.service, HttpServerConfig{
+		CorsAllowedOrigins: []string{"*"},
+		MaxRequestSize:     1024,
+		Auth:               AuthConfig{AnonymousScopes: []Scope{ScopeSubmit, ScopeRead}},
+	})
+}
+
+func (s *ApiSuite) serve(method string, target string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *ApiSuite) assertError(recorder *httptest.ResponseRecorder, status int, code string) {
+	s.Equal(status, recorder.Code)
+	s.Equal("application/json", recorder.Header().Get("Content-Type"))
+	var response ErrorResponse
+	s.Require().Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+	s.Equal(code, response.Code)
+	s.NotEmpty(response.Message)
+}
+
+func (s *ApiSuite) TestRequestNonce() {
+	s.nonces.set(validationTestApp, nonceTestSender, 4)
+	recorder := s.serve(http.MethodPost, "/nonce", `{
+		"app_contract": "`+validationTestApp.Hex()+`",
+		"msg_sender": "`+nonceTestSender.Hex()+`"
+	}`)
+
+	s.Equal(http.StatusOK, recorder.Code)
+	s.Equal("*", recorder.Header().Get("Access-Control-Allow-Origin"))
+	var response NonceResponse
+	s.Require().Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+	s.Equal(uint64(4), response.Nonce)
+}
+
+func (s *ApiSuite) TestGetDomain() {
+	recorder := s.serve(http.MethodGet, "/domain?app="+validationTestApp.Hex(), "")
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+	var domain DomainInfo
+	s.Require().Nil(json.Unmarshal(recorder.Body.Bytes(), &domain))
+	s.Equal(uint64(validationTestChainId), domain.ChainId)
+	s.Equal(uint64(55555), domain.Namespace)
+	s.Equal(uint64(16), domain.MaxPayloadSize)
+
+	// a message built from the response is accepted by the service
+	var typedData apitypes.TypedData
+	document, err := json.Marshal(map[string]any{
+		"domain":      domain.Domain,
+		"types":       domain.Types,
+		"primaryType": domain.PrimaryType,
+		"message": map[string]any{
+			"app":           domain.App,
+			"nonce":         0,
+			"max_gas_price": "10",
+			"data":          "0xdeadbeef",
+		},
+	})
+	s.Require().Nil(err)
+	s.Require().Nil(json.Unmarshal(document, &typedData))
+	key, err := crypto.GenerateKey()
+	s.Require().Nil(err)
+	_, err = s.service.submissionValidator.Validate(context.Background(), signTestTypedData(s.T(), key, typedData))
+	s.Nil(err)
+}
+
+func (s *ApiSuite) TestGetDomainOfUnknownApplications() {
+	s.assertError(s.serve(http.MethodGet, "/domain?app="+validationTestUnknownApp.Hex(), ""),
+		http.StatusNotFound, ErrCodeApplicationNotFound)
+	s.assertError(s.serve(http.MethodGet, "/domain", ""),
+		http.StatusBadRequest, ErrCodeBadRequest)
+}
+
+func (s *ApiSuite) TestRequestNonceRejectsInvalidAddresses() {
+	recorder := s.serve(http.MethodPost, "/nonce", `{"app_contract": "0x1", "msg_sender": "foo"}`)
+	s.assertError(recorder, http.StatusBadRequest, ErrCodeBadRequest)
+}
+
+func (s *ApiSuite) TestItRejectsInvalidPathParameters() {
+	s.assertError(s.serve(http.MethodGet, "/transactions/0x1234", ""),
+		http.StatusBadRequest, ErrCodeBadRequest)
+	s.assertError(s.serve(http.MethodGet, "/applications/foo", ""),
+		http.StatusBadRequest, ErrCodeBadRequest)
+}
+
+func (s *ApiSuite) TestItRespondsWithJSONOnUnknownRoutes() {
+	s.assertError(s.serve(http.MethodGet, "/nonce", ""),
+		http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+	s.assertError(s.serve(http.MethodGet, "/foo", ""),
+		http.StatusNotFound, ErrCodeNotFound)
+}
+
+func (s *ApiSuite) TestItRejectsLargeRequests() {
+	body := `{"app_contract": "` + strings.Repeat("0", 2048) + `"}`
+	s.assertError(s.serve(http.MethodPost, "/nonce", body),
+		http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge)
+
+	// without a known content length, the body is cut while it is read
+	request := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body))
+	request.ContentLength = -1
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, request)
+	s.assertError(recorder, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge)
+}
+
+func (s *ApiSuite) TestItThrottlesSubmissions() {
+	for i := 0; i < 2; i++ {
+		recorder := s.serve(http.MethodPost, "/submit", "not json")
+		s.assertError(recorder, http.StatusBadRequest, ErrCodeBadRequest)
+	}
+
+	recorder := s.serve(http.MethodPost, "/submit", "not json")
+	s.assertError(recorder, http.StatusTooManyRequests, ErrCodeRateLimited)
+	s.Equal("60", recorder.Header().Get("Retry-After"))
+}
+
+func (s *ApiSuite) TestItServesMetrics() {
+	rejected := testutil.ToFloat64(
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageService, ErrCodeRateLimited))
+	s.TestItThrottlesSubmissions()
+	s.Equal(rejected+1, testutil.ToFloat64(
+		metrics.TransactionsRejected.WithLabelValues(metrics.StageService, ErrCodeRateLimited)))
+
+	// metrics need admin credentials, even when anonymous requests may read the API
+	keys, err := ParseApiKeys("operator:admin-secret:admin;reader:read-secret:read")
+	s.Require().Nil(err)
+	handler := NewHandler(s.service, HttpServerConfig{
+		MaxRequestSize: 1024,
+		Auth:           AuthConfig{Keys: keys, AnonymousScopes: []Scope{ScopeSubmit, ScopeRead}},
+	})
+	serve := func(secret string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if secret != "" {
+			request.Header.Set(HeaderApiKey, secret)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	s.assertError(serve(""), http.StatusUnauthorized, ErrCodeUnauthorized)
+	s.assertError(serve("read-secret"), http.StatusForbidden, ErrCodeForbidden)
+
+	recorder := serve("admin-secret")
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	s.Contains(recorder.Body.String(), `espresso_reader_service_requests_total{code="429",path="/submit"}`)
+	s.Contains(recorder.Body.String(), `espresso_reader_transactions_rejected_total{reason="rate_limited",stage="service"}`)
+}
+
+func (s *ApiSuite) TestItTracesSubmissions() {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	request := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader("not json"))
+	request.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	response := httptest.NewRecorder()
+	s.handler.ServeHTTP(response, request)
+	s.assertError(response, http.StatusBadRequest, ErrCodeBadRequest)
+
+	spans := recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("Service::SubmitTransaction", spans[0].Name())
+	s.Equal(trace.SpanKindServer, spans[0].SpanKind())
+	s.Equal("0af7651916cd43dd8448eb211c80319c", spans[0].Parent().TraceID().String())
+	s.Equal(codes.Error, spans[0].Status().Code)
+}
+
+func (s *ApiSuite) TestItChangesTheLogLevel() {
+	keys, err := ParseApiKeys("operator:admin-secret:admin")
+	s.Require().Nil(err)
+	handler := NewHandler(s.service, HttpServerConfig{
+		MaxRequestSize: 1024,
+		Auth:           AuthConfig{Keys: keys, AnonymousScopes: []Scope{ScopeRead}},
+	})
+	serve := func(method string, body string, secret string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/admin/log-level", strings.NewReader(body))
+		if secret != "" {
+			request.Header.Set(HeaderApiKey, secret)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serve(http.MethodGet, "", "admin-secret")
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+	s.JSONEq(`{"level":"info"}`, recorder.Body.String())
+
+	recorder = serve(http.MethodPut, `{"level":"debug"}`, "admin-secret")
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+	s.JSONEq(`{"level":"debug"}`, recorder.Body.String())
+	s.Equal(slog.LevelDebug, s.service.logLevel.Level())
+
+	s.assertError(serve(http.MethodPut, `{"level":"verbose"}`, "admin-secret"),
+		http.StatusBadRequest, ErrCodeBadRequest)
+	s.assertError(serve(http.MethodPut, `{"level":"error"}`, ""),
+		http.StatusUnauthorized, ErrCodeUnauthorized)
+	s.Equal(slog.LevelDebug, s.service.logLevel.Level())
+}
+
+func (s *ApiSuite) preflight(handler http.Handler, origin string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodOptions, "/submit", nil)
+	request.Header.Set("Origin", origin)
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *ApiSuite) TestItAnswersCORSPreflightRequests() {
+	recorder := s.preflight(s.handler, "https://app.example.com")
+	s.Equal(http.StatusNoContent, recorder.Code)
+	s.Equal("*", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func (s *ApiSuite) TestItOnlyAllowsConfiguredOrigins() {
+	handler := NewHandler(s.service, HttpServerConfig{
+		CorsAllowedOrigins: []string{"https://app.example.com"},
+		MaxRequestSize:     1024,
+	})
+
+	recorder := s.preflight(handler, "https://app.example.com")
+	s.Equal(http.StatusNoContent, recorder.Code)
+	s.Equal("https://app.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	recorder = s.preflight(handler, "https://evil.example.com")
+	s.Empty(recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func (s *ApiSuite) TestParseTrustedProxies() {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10", "10.1.2.3/16", "::1"})
+	s.Require().Nil(err)
+	s.Equal([]netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.10/32"),
+		netip.MustParsePrefix("10.1.0.0/16"),
+		netip.MustParsePrefix("::1/128"),
+	}, proxies)
+
+	_, err = ParseTrustedProxies([]string{"proxy.example.com"})
+	s.ErrorContains(err, "invalid trusted proxy 'proxy.example.com'")
+}
+
+func (s *ApiSuite) TestItTrustsTheForwardedForHeaderOfTrustedProxies() {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	s.Require().Nil(err)
+	handler := forwardedFor(proxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(clientIp(r)))
+	}))
+
+	for _, test := range []struct {
+		remoteAddr   string
+		forwardedFor []string
+		expectedIp   string
+		description  string
+	}{
+		{"203.0.113.7:1234", nil, "203.0.113.7", "not behind a proxy"},
+		{"203.0.113.7:1234", []string{"198.51.100.1"}, "203.0.113.7", "not a trusted proxy"},
+		{"10.0.0.1:1234", []string{"198.51.100.1"}, "198.51.100.1", "trusted proxy"},
+		{"10.0.0.1:1234", nil, "10.0.0.1", "trusted proxy without the header"},
+		{"10.0.0.1:1234", []string{"198.51.100.1, 10.0.0.2"}, "198.51.100.1", "chain of trusted proxies"},
+		{"10.0.0.1:1234", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1", "forged by the client"},
+		{"10.0.0.1:1234", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1", "many headers"},
+		{"10.0.0.1:1234", []string{"garbage"}, "10.0.0.1", "invalid header"},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = test.remoteAddr
+		for _, value := range test.forwardedFor {
+			request.Header.Add("X-Forwarded-For", value)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		s.Equal(test.expectedIp, recorder.Body.String(), test.description)
+	}
+}
+
+func (s *ApiSuite) TestItThrottlesTheClientsOfTrustedProxiesSeparately() {
+	// httptest requests come from 192.0.2.1
+	proxies, err := ParseTrustedProxies([]string{"192.0.2.0/24"})
+	s.Require().Nil(err)
+	handler := NewHandler(s.service, HttpServerConfig{
+		TrustedProxies: proxies,
+		MaxRequestSize: 1024,
+		Auth:           AuthConfig{AnonymousScopes: []Scope{ScopeSubmit, ScopeRead}},
+	})
+	submit := func(client string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader("not json"))
+		request.Header.Set("X-Forwarded-For", client)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	for i := 0; i < 2; i++ {
+		s.assertError(submit("198.51.100.1"), http.StatusBadRequest, ErrCodeBadRequest)
+	}
+	s.assertError(submit("198.51.100.1"), http.StatusTooManyRequests, ErrCodeRateLimited)
+	s.assertError(submit("198.51.100.2"), http.StatusBadRequest, ErrCodeBadRequest)
+}
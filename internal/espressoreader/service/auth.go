@@ -0,0 +1,318 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope is a set of endpoints an API key grants access to
+type Scope string
+
+const (
+	ScopeSubmit Scope = "submit"
+	ScopeRead   Scope = "read"
+	ScopeAdmin  Scope = "admin"
+)
+
+// Headers used to authenticate requests
+const (
+	HeaderApiKey    = "X-API-Key"
+	HeaderApiKeyId  = "X-API-Key-Id"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderSignature = "X-Signature"
+)
+
+// ApiKey identifies a client of the Espresso service.
+// Clients either send the secret itself in the X-API-Key header,
+// or sign their requests with it (see SignRequest).
+type ApiKey struct {
+	Id     string
+	Secret string
+	Scopes []Scope
+}
+
+func (k ApiKey) String() string {
+	return k.Id
+}
+
+// AuthConfig configures the authentication of the Espresso service
+type AuthConfig struct {
+	Keys []ApiKey
+	// Scopes granted to requests without credentials
+	AnonymousScopes []Scope
+	// How far the X-Timestamp of a signed request may be from the server clock
+	MaxClockSkew time.Duration
+}
+
+// ParseScopes parses a comma-separated list of scopes
+func ParseScopes(s string) ([]Scope, error) {
+	scopes := []Scope{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		scope := Scope(item)
+		if !slices.Contains([]Scope{ScopeSubmit, ScopeRead, ScopeAdmin}, scope) {
+			return nil, fmt.Errorf("invalid scope '%s'", item)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// ParseApiKeys parses API keys in the `<id>:<secret>:<scope>[,<scope>...]` format.
+// Keys are separated by new lines or semicolons, and lines starting with # are ignored.
+func ParseApiKeys(s string) ([]ApiKey, error) {
+	keys := []ApiKey{}
+	ids := map[string]bool{}
+	for _, entry := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ';' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		fields := strings.Split(entry, ":")
+		if len(fields) != 3 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("API keys must be in the <id>:<secret>:<scopes> format")
+		}
+		scopes, err := ParseScopes(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("API key %s: %w", fields[0], err)
+		}
+		if ids[fields[0]] {
+			return nil, fmt.Errorf("duplicate API key %s", fields[0])
+		}
+		ids[fields[0]] = true
+		keys = append(keys, ApiKey{Id: fields[0], Secret: fields[1], Scopes: scopes})
+	}
+	return keys, nil
+}
+
+// LoadApiKeys parses the API keys set in the configuration and in the keys file, if any
+func LoadApiKeys(keys string, keysFile string) ([]ApiKey, error) {
+	if keysFile != "" {
+		contents, err := os.ReadFile(keysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API keys file: %w", err)
+		}
+		keys = keys + "\n" + string(contents)
+	}
+	return ParseApiKeys(keys)
+}
+
+// SignRequest returns the X-Signature of a request with the given timestamp and body
+func SignRequest(secret string, timestamp int64, method string, path string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d\n%s\n%s\n%s", timestamp, method, path, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	errInvalidApiKey    = errors.New("invalid API key")
+	errInvalidSignature = errors.New("invalid request signature")
+	errExpiredSignature = errors.New("request timestamp is too far from the server clock")
+	errReplayedRequest  = errors.New("request signature was already used")
+)
+
+type authenticator struct {
+	keys            []ApiKey
+	anonymousScopes []Scope
+	maxClockSkew    time.Duration
+	now             func() time.Time
+
+	// signatures accepted within the clock skew, by when they expire
+	signatures      map[string]time.Time
+	lastPrune       time.Time
+	signaturesMutex sync.Mutex
+}
+
+func newAuthenticator(config AuthConfig) *authenticator {
+	return &authenticator{
+		keys:            config.Keys,
+		anonymousScopes: config.AnonymousScopes,
+		maxClockSkew:    config.MaxClockSkew,
+		now:             time.Now,
+		signatures:      map[string]time.Time{},
+	}
+}
+
+// useSignature rejects a signature already accepted, so a captured request can't be replayed.
+// Signatures are remembered until their timestamp is too old to be accepted anyway.
+func (a *authenticator) useSignature(signature string, timestamp time.Time) error {
+	a.signaturesMutex.Lock()
+	defer a.signaturesMutex.Unlock()
+	now := a.now()
+	if now.Sub(a.lastPrune) > a.maxClockSkew {
+		for s, expiry := range a.signatures {
+			if now.After(expiry) {
+				delete(a.signatures, s)
+			}
+		}
+		a.lastPrune = now
+	}
+	if _, ok := a.signatures[signature]; ok {
+		return errReplayedRequest
+	}
+	a.signatures[signature] = timestamp.Add(a.maxClockSkew)
+	return nil
+}
+
+// identify returns the key the request was authenticated with,
+// or nil if the request has no credentials
+func (a *authenticator) identify(r *http.Request) (*ApiKey, error) {
+	if secret := r.Header.Get(HeaderApiKey); secret != "" {
+		for i := range a.keys {
+			if subtle.ConstantTimeCompare([]byte(a.keys[i].Secret), []byte(secret)) == 1 {
+				return &a.keys[i], nil
+			}
+		}
+		return nil, errInvalidApiKey
+	}
+
+	signature := r.Header.Get(HeaderSignature)
+	if signature == "" {
+		return nil, nil
+	}
+	id := r.Header.Get(HeaderApiKeyId)
+	index := slices.IndexFunc(a.keys, func(key ApiKey) bool { return key.Id == id })
+	if index < 0 {
+		return nil, errInvalidApiKey
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	signedAt := time.Unix(timestamp, 0)
+	skew := a.now().Sub(signedAt)
+	if skew > a.maxClockSkew || -skew > a.maxClockSkew {
+		return nil, errExpiredSignature
+	}
+
+	// the body is signed, so it must be read here and put back for the handler
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	key := &a.keys[index]
+	expected := SignRequest(key.Secret, timestamp, r.Method, r.URL.Path, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, errInvalidSignature
+	}
+	if err := a.useSignature(expected, signedAt); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// requiredScopes returns the scopes of the operation, as set by the generated router
+func requiredScopes(r *http.Request) []Scope {
+	var scopes []Scope
+	for _, key := range []string{ApiKeyScopes, HmacScopes} {
+		names, _ := r.Context().Value(key).([]string)
+		for _, name := range names {
+			if !slices.Contains(scopes, Scope(name)) {
+				scopes = append(scopes, Scope(name))
+			}
+		}
+	}
+	return scopes
+}
+
+// requireScopes sets the scopes of endpoints outside of the API, as the generated router does for its operations
+func requireScopes(scopes ...Scope) func(http.Handler) http.Handler {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ApiKeyScopes, names)))
+		})
+	}
+}
+
+func grants(granted []Scope, required []Scope) bool {
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// middleware rejects requests without the scopes of the operation they call.
+// Rejections are audit-logged.
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := requiredScopes(r)
+		if len(required) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := a.identify(r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeRequestTooLarge(w, maxBytesErr.Limit)
+				return
+			}
+			a.audit(r, nil, required, err.Error())
+			writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, err.Error())
+			return
+		}
+		if key == nil {
+			if grants(a.anonymousScopes, required) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			a.audit(r, nil, required, "missing credentials")
+			writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized,
+				fmt.Sprintf("an API key with the %v scopes is required", required))
+			return
+		}
+		if !grants(key.Scopes, required) {
+			a.audit(r, key, required, "missing scopes")
+			writeError(w, http.StatusForbidden, ErrCodeForbidden,
+				fmt.Sprintf("API key %s lacks the %v scopes", key.Id, required))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *authenticator) audit(r *http.Request, key *ApiKey, required []Scope, reason string) {
+	keyId := r.Header.Get(HeaderApiKeyId)
+	if key != nil {
+		keyId = key.Id
+	}
+	slog.Warn("espresso service: rejected unauthorized request",
+		"audit", true,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"ip", clientIp(r),
+		"key", keyId,
+		"scopes", required,
+		"reason", reason)
+}
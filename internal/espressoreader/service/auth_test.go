@@ -0,0 +1,175 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthSuite struct {
+	suite.Suite
+	handler       http.Handler
+	authenticator *authenticator
+	clock         time.Time
+}
+
+func TestAuthSuite(t *testing.T) {
+	suite.Run(t, new(AuthSuite))
+}
+
+func (s *AuthSuite) SetupTest() {
+	keys, err := ParseApiKeys("reader:read-secret:read;relayer:relay-secret:submit,read")
+	s.Require().Nil(err)
+
+	s.clock = time.Unix(1700000000, 0)
+	authenticator := newAuthenticator(AuthConfig{
+		Keys:            keys,
+		AnonymousScopes: []Scope{ScopeRead},
+		MaxClockSkew:    time.Minute,
+	})
+	authenticator.now = func() time.Time { return s.clock }
+	s.authenticator = authenticator
+
+	// scopes are set by the generated router, like it does for the real endpoints
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		s.Nil(err)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+	router := http.NewServeMux()
+	for path, scope := range map[string]string{"/submit": "submit", "/status": "read"} {
+		handler := authenticator.middleware(ok)
+		router.Handle(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, ApiKeyScopes, []string{scope})
+			handler.ServeHTTP(w, r.WithContext(ctx))
+		}))
+	}
+	s.handler = router
+}
+
+func (s *AuthSuite) serve(request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *AuthSuite) signedRequest(id string, secret string, timestamp time.Time, body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body))
+	request.Header.Set(HeaderApiKeyId, id)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set(HeaderSignature,
+		SignRequest(secret, timestamp.Unix(), http.MethodPost, "/submit", []byte(body)))
+	return request
+}
+
+func (s *AuthSuite) TestItGrantsAnonymousScopes() {
+	s.Equal(http.StatusOK, s.serve(httptest.NewRequest(http.MethodGet, "/status", nil)).Code)
+
+	recorder := s.serve(httptest.NewRequest(http.MethodPost, "/submit", nil))
+	s.Equal(http.StatusUnauthorized, recorder.Code)
+	s.Contains(recorder.Body.String(), ErrCodeUnauthorized)
+}
+
+func (s *AuthSuite) TestItAcceptsApiKeys() {
+	request := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	request.Header.Set(HeaderApiKey, "relay-secret")
+	s.Equal(http.StatusOK, s.serve(request).Code)
+}
+
+func (s *AuthSuite) TestItRejectsInvalidApiKeys() {
+	request := httptest.NewRequest(http.MethodGet, "/status", nil)
+	request.Header.Set(HeaderApiKey, "wrong-secret")
+	s.Equal(http.StatusUnauthorized, s.serve(request).Code)
+}
+
+func (s *AuthSuite) TestItChecksScopes() {
+	request := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	request.Header.Set(HeaderApiKey, "read-secret")
+	recorder := s.serve(request)
+	s.Equal(http.StatusForbidden, recorder.Code)
+	s.Contains(recorder.Body.String(), ErrCodeForbidden)
+}
+
+func (s *AuthSuite) TestItAcceptsSignedRequests() {
+	recorder := s.serve(s.signedRequest("relayer", "relay-secret", s.clock, "payload"))
+	s.Equal(http.StatusOK, recorder.Code)
+	// the handler still gets the body
+	s.Equal("payload", recorder.Body.String())
+}
+
+func (s *AuthSuite) TestItRejectsInvalidSignatures() {
+	for _, request := range []*http.Request{
+		s.signedRequest("relayer", "wrong-secret", s.clock, "payload"),
+		s.signedRequest("unknown", "relay-secret", s.clock, "payload"),
+		s.signedRequest("relayer", "relay-secret", s.clock.Add(-2*time.Minute), "payload"),
+		s.signedRequest("relayer", "relay-secret", s.clock.Add(2*time.Minute), "payload"),
+	} {
+		s.Equal(http.StatusUnauthorized, s.serve(request).Code)
+	}
+
+	tampered := s.signedRequest("relayer", "relay-secret", s.clock, "payload")
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader("other")).Body
+	s.Equal(http.StatusUnauthorized, s.serve(tampered).Code)
+}
+
+func (s *AuthSuite) TestItRejectsReplayedRequests() {
+	s.Equal(http.StatusOK, s.serve(s.signedRequest("relayer", "relay-secret", s.clock, "payload")).Code)
+
+	recorder := s.serve(s.signedRequest("relayer", "relay-secret", s.clock, "payload"))
+	s.Equal(http.StatusUnauthorized, recorder.Code)
+	s.Contains(recorder.Body.String(), "already used")
+
+	// a new request is signed with another timestamp or body
+	s.Equal(http.StatusOK, s.serve(s.signedRequest("relayer", "relay-secret", s.clock, "other")).Code)
+	s.clock = s.clock.Add(time.Second)
+	s.Equal(http.StatusOK, s.serve(s.signedRequest("relayer", "relay-secret", s.clock, "payload")).Code)
+}
+
+func (s *AuthSuite) TestItForgetsExpiredSignatures() {
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusOK, s.serve(s.signedRequest("relayer", "relay-secret", s.clock, "payload")).Code)
+		s.clock = s.clock.Add(2 * time.Minute)
+	}
+	// the signatures are kept only while their timestamp is accepted
+	s.Len(s.authenticator.signatures, 1)
+}
+
+func (s *AuthSuite) TestParseApiKeys() {
+	keys, err := ParseApiKeys("# comment\nbot:secret:submit, read\n\nadmin:other:admin;")
+	s.Require().Nil(err)
+	s.Equal([]ApiKey{
+		{Id: "bot", Secret: "secret", Scopes: []Scope{ScopeSubmit, ScopeRead}},
+		{Id: "admin", Secret: "other", Scopes: []Scope{ScopeAdmin}},
+	}, keys)
+
+	for _, invalid := range []string{"bot:secret", "bot::read", "bot:secret:write", "a:b:read;a:c:read"} {
+		_, err := ParseApiKeys(invalid)
+		s.Error(err, invalid)
+	}
+}
+
+func (s *AuthSuite) TestLoadApiKeys() {
+	path := filepath.Join(s.T().TempDir(), "keys")
+	s.Require().Nil(os.WriteFile(path, []byte("file:file-secret:read\n"), 0o600))
+
+	keys, err := LoadApiKeys("env:env-secret:submit", path)
+	s.Require().Nil(err)
+	s.Len(keys, 2)
+
+	_, err = LoadApiKeys("", filepath.Join(s.T().TempDir(), "missing"))
+	s.Error(err)
+}
@@ -0,0 +1,144 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrorRecorder is a slog.Handler that remembers the last error logged,
+// overall and by application, before passing the records on to the next handler.
+// Errors are attributed to the application in their "app" attribute, if any.
+type ErrorRecorder struct {
+	next  slog.Handler
+	attrs []slog.Attr
+	state *errorRecorderState
+}
+
+type errorRecorderState struct {
+	mutex sync.Mutex
+	last  *ErrorRecord
+	byApp map[common.Address]ErrorRecord
+}
+
+func NewErrorRecorder(next slog.Handler) *ErrorRecorder {
+	return &ErrorRecorder{
+		next:  next,
+		state: &errorRecorderState{byApp: make(map[common.Address]ErrorRecord)},
+	}
+}
+
+// Last returns the last error logged, whatever its application
+func (r *ErrorRecorder) Last() *ErrorRecord {
+	r.state.mutex.Lock()
+	defer r.state.mutex.Unlock()
+	if r.state.last == nil {
+		return nil
+	}
+	record := *r.state.last
+	return &record
+}
+
+// LastOf returns the last error logged for the application
+func (r *ErrorRecorder) LastOf(app common.Address) *ErrorRecord {
+	r.state.mutex.Lock()
+	defer r.state.mutex.Unlock()
+	record, ok := r.state.byApp[app]
+	if !ok {
+		return nil
+	}
+	return &record
+}
+
+func (r *ErrorRecorder) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelError || r.next.Enabled(ctx, level)
+}
+
+func (r *ErrorRecorder) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		r.record(record)
+	}
+	if !r.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return r.next.Handle(ctx, record)
+}
+
+func (r *ErrorRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ErrorRecorder{
+		next:  r.next.WithAttrs(attrs),
+		attrs: append(append([]slog.Attr{}, r.attrs...), attrs...),
+		state: r.state,
+	}
+}
+
+func (r *ErrorRecorder) WithGroup(name string) slog.Handler {
+	return &ErrorRecorder{next: r.next.WithGroup(name), attrs: r.attrs, state: r.state}
+}
+
+func (r *ErrorRecorder) record(record slog.Record) {
+	var (
+		app    *common.Address
+		reason string
+	)
+	visit := func(attr slog.Attr) bool {
+		switch attr.Key {
+		case "app":
+			app = appAddressOf(attr.Value.Any())
+		case "error", "err":
+			reason = attr.Value.String()
+		}
+		return true
+	}
+	for _, attr := range r.attrs {
+		visit(attr)
+	}
+	record.Attrs(visit)
+
+	message := record.Message
+	if reason != "" {
+		message = fmt.Sprintf("%s: %s", message, reason)
+	}
+	errorRecord := ErrorRecord{Message: message, Time: record.Time}
+	if errorRecord.Time.IsZero() {
+		errorRecord.Time = time.Now()
+	}
+
+	r.state.mutex.Lock()
+	defer r.state.mutex.Unlock()
+	r.state.last = &errorRecord
+	if app != nil {
+		r.state.byApp[*app] = errorRecord
+	}
+}
+
+// appAddressOf returns the address of the application logged in an "app" attribute
+func appAddressOf(value any) *common.Address {
+	switch app := value.(type) {
+	case common.Address:
+		return &app
+	case *common.Address:
+		return app
+	case model.Application:
+		return &app.ContractAddress
+	case *model.Application:
+		if app != nil {
+			return &app.ContractAddress
+		}
+	case string:
+		if common.IsHexAddress(app) {
+			address := common.HexToAddress(app)
+			return &address
+		}
+	}
+	return nil
+}
@@ -5,46 +5,55 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ZzzzHui/espresso-reader/internal/espressoreader"
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader/retrypolicy"
+	evmreaderservice "github.com/ZzzzHui/espresso-reader/internal/evmreader/service"
 	"github.com/ZzzzHui/espresso-reader/internal/repository"
+	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
 
-	"github.com/EspressoSystems/espresso-sequencer-go/client"
-	"github.com/EspressoSystems/espresso-sequencer-go/types"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"golang.org/x/sync/errgroup"
 )
 
-// app address => sender address => nonce
-var nonceCache map[common.Address]map[common.Address]uint64
-
 // Service to manage InputReader lifecycle
 type EspressoReaderService struct {
 	blockchainHttpEndpoint  string
 	blockchainWsEndpoint    string
+	pollingInterval         time.Duration
 	database                *repository.Database
+	applications            ApplicationRepository
 	EspressoBaseUrl         string
 	EspressoStartingBlock   uint64
 	EspressoNamespace       uint64
-	maxRetries              uint64
-	maxDelay                time.Duration
+	retryPolicy             *retry.Policy
 	chainId                 uint64
 	inputBoxDeploymentBlock uint64
-	espressoServiceEndpoint string
+	httpConfig              HttpServerConfig
+	nonceManager            *NonceManager
+	submissionValidator     *SubmissionValidator
+	rateLimiter             *SubmitRateLimiter
+	ingestionLimits         espressoreader.IngestionLimits
+	statusRepository        StatusRepository
+	heads                   ChainHeads
+	errors                  *ErrorRecorder
+	readyMaxLag             atomic.Uint64
+	readerStarted           atomic.Bool
+	logLevel                *slog.LevelVar
+	// the last applied settings, which Apply compares the new ones with
+	settings      Settings
+	settingsMutex sync.Mutex
 }
 
 func NewEspressoReaderService(
 	blockchainHttpEndpoint string,
 	blockchainWsEndpoint string,
+	pollingInterval time.Duration,
 	database *repository.Database,
 	EspressoBaseUrl string,
 	EspressoStartingBlock uint64,
@@ -53,53 +62,118 @@ func NewEspressoReaderService(
 	maxDelay time.Duration,
 	chainId uint64,
 	inputBoxDeploymentBlock uint64,
-	espressoServiceEndpoint string,
-) *EspressoReaderService {
-	return &EspressoReaderService{
+	httpConfig HttpServerConfig,
+	noncePendingTimeout time.Duration,
+	maxPayloadSize uint64,
+	rateLimitConfig RateLimitConfig,
+	ingestionLimits espressoreader.IngestionLimits,
+	readyMaxLag uint64,
+	logLevel *slog.LevelVar,
+	errors *ErrorRecorder,
+) (*EspressoReaderService, error) {
+	heads, err := dialChainHeads(blockchainHttpEndpoint, EspressoBaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	nonceManager := NewNonceManager(database, noncePendingTimeout)
+	service := &EspressoReaderService{
 		blockchainHttpEndpoint:  blockchainHttpEndpoint,
 		blockchainWsEndpoint:    blockchainWsEndpoint,
+		pollingInterval:         pollingInterval,
 		database:                database,
+		applications:            database,
 		EspressoBaseUrl:         EspressoBaseUrl,
 		EspressoStartingBlock:   EspressoStartingBlock,
 		EspressoNamespace:       EspressoNamespace,
-		maxRetries:              maxRetries,
-		maxDelay:                maxDelay,
+		retryPolicy:             retry.NewPolicy(maxRetries, maxDelay),
 		chainId:                 chainId,
 		inputBoxDeploymentBlock: inputBoxDeploymentBlock,
-		espressoServiceEndpoint: espressoServiceEndpoint,
-	}
+		httpConfig:              httpConfig,
+		nonceManager:            nonceManager,
+		submissionValidator:     NewSubmissionValidator(database, nonceManager, chainId, maxPayloadSize),
+		rateLimiter:             NewSubmitRateLimiter(rateLimitConfig),
+		ingestionLimits:         ingestionLimits,
+		statusRepository:        statusDatabase{database},
+		heads:                   heads,
+		errors:                  errors,
+		logLevel:                logLevel,
+		settings: Settings{
+			LogLevel:    logLevel.Level(),
+			MaxRetries:  maxRetries,
+			MaxDelay:    maxDelay,
+			RateLimits:  rateLimitConfig,
+			ReadyMaxLag: readyMaxLag,
+		},
+	}
+	service.readyMaxLag.Store(readyMaxLag)
+	return service, nil
 }
 
 func (s *EspressoReaderService) Start(
 	ctx context.Context,
 	ready chan<- struct{},
 ) error {
-
-	evmReader := s.setupEvmReader(ctx, s.database)
-
-	espressoReader := espressoreader.NewEspressoReader(s.EspressoBaseUrl, s.EspressoStartingBlock, s.EspressoNamespace, s.database, evmReader, s.chainId, s.inputBoxDeploymentBlock)
-
-	go s.setupNonceHttpServer()
-
-	return espressoReader.Run(ctx, ready)
+	defer s.heads.Close()
+
+	evmReader, closeEvmReader := s.setupEvmReader(ctx, s.database)
+	defer closeEvmReader()
+
+	espressoReader := espressoreader.NewEspressoReader(s.EspressoBaseUrl, s.EspressoStartingBlock, s.EspressoNamespace, s.database, evmReader, s.chainId, s.inputBoxDeploymentBlock, s.ingestionLimits)
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		s.nonceManager.Run(ctx)
+		return nil
+	})
+	group.Go(func() error {
+		s.rateLimiter.Run(ctx)
+		return nil
+	})
+	group.Go(func() error {
+		return serveHttp(ctx, s.httpConfig, NewHandler(s, s.httpConfig))
+	})
+	// the service is ready for /readyz once the reader went through the applications
+	readerReady := make(chan struct{}, 1)
+	group.Go(func() error {
+		select {
+		case <-readerReady:
+			s.readerStarted.Store(true)
+			ready <- struct{}{}
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	group.Go(func() error {
+		return espressoReader.Run(ctx, readerReady)
+	})
+	return group.Wait()
 }
 
 func (s *EspressoReaderService) String() string {
 	return "espressoreader"
 }
 
-func (s *EspressoReaderService) setupEvmReader(ctx context.Context, database *repository.Database) *evmreader.EvmReader {
+func (s *EspressoReaderService) setupEvmReader(
+	ctx context.Context,
+	database *repository.Database,
+) (*evmreader.EvmReader, func()) {
 	client, err := ethclient.DialContext(ctx, s.blockchainHttpEndpoint)
 	if err != nil {
 		slog.Error("eth client http", "error", err)
 	}
-	defer client.Close()
+	ethClient := retrypolicy.NewEhtClientWithRetryPolicy(client, s.retryPolicy)
 
-	wsClient, err := ethclient.DialContext(ctx, s.blockchainWsEndpoint)
+	// without a WebSocket endpoint, the reader polls the HTTP one
+	endpoint := s.blockchainWsEndpoint
+	if endpoint == "" {
+		endpoint = s.blockchainHttpEndpoint
+	}
+	blockWatcher, closeBlockWatcher, err := evmreaderservice.DialBlockWatcher(
+		ctx, endpoint, ethClient, s.retryPolicy, s.pollingInterval)
 	if err != nil {
-		slog.Error("eth client ws", "error", err)
+		slog.Error("block watcher", "error", err)
+		closeBlockWatcher = func() {}
 	}
-	defer wsClient.Close()
 
 	config, err := database.GetNodeConfig(ctx)
 	if err != nil {
@@ -111,12 +185,12 @@ func (s *EspressoReaderService) setupEvmReader(ctx context.Context, database *re
 		slog.Error("input source", "error", err)
 	}
 
-	contractFactory := retrypolicy.NewEvmReaderContractFactory(client, s.maxRetries, s.maxDelay)
+	contractFactory := retrypolicy.NewEvmReaderContractFactory(client, s.retryPolicy)
 
 	evmReader := evmreader.NewEvmReader(
-		retrypolicy.NewEhtClientWithRetryPolicy(client, s.maxRetries, s.maxDelay),
-		retrypolicy.NewEthWsClientWithRetryPolicy(wsClient, s.maxRetries, s.maxDelay),
-		retrypolicy.NewInputSourceWithRetryPolicy(inputSource, s.maxRetries, s.maxDelay),
+		ethClient,
+		blockWatcher,
+		retrypolicy.NewInputSourceWithRetryPolicy(inputSource, s.retryPolicy),
 		database,
 		config.InputBoxDeploymentBlock,
 		config.DefaultBlock,
@@ -124,152 +198,10 @@ func (s *EspressoReaderService) setupEvmReader(ctx context.Context, database *re
 		true,
 	)
 
-	return &evmReader
-}
-
-func (s *EspressoReaderService) setupNonceHttpServer() {
-	nonceCache = make(map[common.Address]map[common.Address]uint64)
-
-	http.HandleFunc("/nonce", s.requestNonce)
-	http.HandleFunc("/submit", s.submit)
-
-	http.ListenAndServe(s.espressoServiceEndpoint, nil)
-}
-
-type NonceRequest struct {
-	// AppContract App contract address
-	AppContract string `json:"app_contract"`
-
-	// MsgSender Message sender address
-	MsgSender string `json:"msg_sender"`
-}
-
-type NonceResponse struct {
-	Nonce uint64 `json:"nonce"`
-}
-
-func (s *EspressoReaderService) requestNonce(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers")
-	if r.Method != http.MethodPost {
-		return
-	}
-
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		slog.Error("could not read body", "err", err)
-	}
-	nonceRequest := &NonceRequest{}
-	if err := json.Unmarshal(body, nonceRequest); err != nil {
-		fmt.Println(err)
-	}
-
-	senderAddress := common.HexToAddress(nonceRequest.MsgSender)
-	applicationAddress := common.HexToAddress(nonceRequest.AppContract)
-
-	var nonce uint64
-	if nonceCache[applicationAddress] == nil {
-		nonceCache[applicationAddress] = make(map[common.Address]uint64)
-	}
-	if nonceCache[applicationAddress][senderAddress] == 0 {
-		ctx := r.Context()
-		nonce = s.queryNonceFromDb(ctx, senderAddress, applicationAddress)
-		nonceCache[applicationAddress][senderAddress] = nonce
-	} else {
-		nonce = nonceCache[applicationAddress][senderAddress]
-	}
-
-	slog.Debug("got nonce request", "senderAddress", senderAddress, "applicationAddress", applicationAddress)
-
-	nonceResponse := NonceResponse{Nonce: nonce}
-	if err != nil {
-		slog.Error("error json marshal nonce response", "err", err)
-	}
-
-	err = json.NewEncoder(w).Encode(nonceResponse)
-	if err != nil {
-		slog.Info("Internal server error",
-			"service", "espresso nonce querier",
-			"err", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-}
-
-func (s *EspressoReaderService) queryNonceFromDb(
-	ctx context.Context,
-	senderAddress common.Address,
-	applicationAddress common.Address) uint64 {
-	nonce, err := s.database.GetEspressoNonce(ctx, senderAddress, applicationAddress)
-	if err != nil {
-		slog.Error("failed to get espresso nonce", "error", err)
-	}
-
-	return nonce
-}
-
-type SubmitResponse struct {
-	Id string `json:"id,omitempty"`
-}
-
-func (s *EspressoReaderService) submit(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers")
-	if r.Method != http.MethodPost {
-		return
-	}
-
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		slog.Error("could not read body", "err", err)
-	}
-	slog.Debug("got submit request", "request body", string(body))
-
-	client := client.NewClient(s.EspressoBaseUrl)
-	ctx := r.Context()
-	var tx types.Transaction
-	tx.Namespace = s.EspressoNamespace
-	tx.Payload = []byte(base64.StdEncoding.EncodeToString(body))
-	_, err = client.SubmitTransaction(ctx, tx)
-	if err != nil {
-		slog.Error("espresso tx submit error", "err", err)
-		return
-	}
-
-	msgSender, typedData, sigHash, err := espressoreader.ExtractSigAndData(string(tx.Payload))
-	if err != nil {
-		slog.Error("transaction not correctly formatted", "error", err)
-		return
-	}
-	submitResponse := SubmitResponse{Id: sigHash}
-
-	err = json.NewEncoder(w).Encode(submitResponse)
-	if err != nil {
-		slog.Info("Internal server error",
-			"service", "espresso submit endpoint",
-			"err", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// update nonce cache
-	appAddressStr := typedData.Message["app"].(string)
-	appAddress := common.HexToAddress(appAddressStr)
-	if nonceCache[appAddress] == nil {
-		slog.Error("Should query nonce before submit")
-		return
-	}
-	nonceInRequest := uint64(typedData.Message["nonce"].(float64))
-	if nonceCache[appAddress][msgSender] == 0 {
-		ctx := r.Context()
-		nonceInDb := s.queryNonceFromDb(ctx, msgSender, appAddress)
-		if nonceInRequest != nonceInDb {
-			slog.Error("Nonce in request is incorrect")
-			return
+	return &evmReader, func() {
+		closeBlockWatcher()
+		if client != nil {
+			client.Close()
 		}
-		nonceCache[appAddress][msgSender] = nonceInDb + 1
-	} else {
-		nonceCache[appAddress][msgSender]++
 	}
 }
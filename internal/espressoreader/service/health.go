@@ -0,0 +1,116 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+)
+
+// Names of the readiness checks
+const (
+	checkDatabase = "database"
+	checkL1       = "l1"
+	checkEspresso = "espresso"
+	checkReader   = "reader"
+	checkLag      = "lag"
+)
+
+// probes are the liveness and readiness endpoints, served next to the API
+type probes interface {
+	healthz(w http.ResponseWriter, r *http.Request)
+	readyz(w http.ResponseWriter, r *http.Request)
+}
+
+var _ probes = (*EspressoReaderService)(nil)
+
+type healthCheck struct {
+	Name  string `json:"name"`
+	Ok    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks,omitempty"`
+}
+
+// healthz responds while the service is up, for liveness probes
+func (s *EspressoReaderService) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// readyz responds with 200 when every dependency of the reader is available
+// and it caught up with Espresso, and with 503 otherwise, for readiness probes
+func (s *EspressoReaderService) readyz(w http.ResponseWriter, r *http.Request) {
+	checks := s.readinessChecks(r.Context())
+	response := healthResponse{Status: "ok", Checks: checks}
+	status := http.StatusOK
+	for _, check := range checks {
+		if !check.Ok {
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	writeJSON(w, status, response)
+}
+
+func newHealthCheck(name string, err error) healthCheck {
+	if err != nil {
+		return healthCheck{Name: name, Error: err.Error()}
+	}
+	return healthCheck{Name: name, Ok: true}
+}
+
+func (s *EspressoReaderService) readinessChecks(ctx context.Context) []healthCheck {
+	checks := []healthCheck{newHealthCheck(checkDatabase, s.statusRepository.CheckSchema(ctx))}
+
+	headsCtx, cancel := context.WithTimeout(ctx, statusHeadsTimeout)
+	defer cancel()
+	_, err := s.heads.L1FinalizedBlock(headsCtx)
+	checks = append(checks, newHealthCheck(checkL1, err))
+	espressoLatestHeight, espressoErr := s.heads.EspressoLatestHeight(headsCtx)
+	checks = append(checks, newHealthCheck(checkEspresso, espressoErr))
+
+	if !s.readerStarted.Load() {
+		checks = append(checks, newHealthCheck(checkReader,
+			fmt.Errorf("the reader has not gone through the applications yet")))
+	} else {
+		checks = append(checks, newHealthCheck(checkReader, nil))
+	}
+
+	if espressoErr != nil {
+		err = fmt.Errorf("the latest Espresso height is unknown")
+	} else {
+		err = s.checkLag(ctx, espressoLatestHeight)
+	}
+	return append(checks, newHealthCheck(checkLag, err))
+}
+
+// checkLag fails if a running application is more than readyMaxLag blocks behind Espresso
+func (s *EspressoReaderService) checkLag(ctx context.Context, espressoLatestHeight uint64) error {
+	apps, err := s.statusRepository.GetAllApplications(ctx)
+	if err != nil {
+		return err
+	}
+	maxLag := s.readyMaxLag.Load()
+	for _, app := range apps {
+		if app.Status != model.ApplicationStatusRunning {
+			continue
+		}
+		lastProcessedEspressoBlock, err := s.statusRepository.GetLastProcessedEspressoBlock(
+			ctx, app.ContractAddress)
+		if err != nil {
+			return err
+		}
+		if blocks := *lag(&espressoLatestHeight, lastProcessedEspressoBlock); blocks > maxLag {
+			return fmt.Errorf("application %s is %d Espresso blocks behind, more than %d",
+				app.ContractAddress, blocks, maxLag)
+		}
+	}
+	return nil
+}
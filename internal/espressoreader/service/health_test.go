@@ -0,0 +1,163 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthSuite struct {
+	suite.Suite
+	repository *fakeStatusRepository
+	heads      *fakeChainHeads
+	service    *EspressoReaderService
+	handler    http.Handler
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthSuite))
+}
+
+func (s *HealthSuite) SetupTest() {
+	s.repository = &fakeStatusRepository{
+		apps: []model.Application{{
+			ContractAddress: validationTestApp,
+			Status:          model.ApplicationStatusRunning,
+		}, {
+			ContractAddress: validationTestDisabledApp,
+			Status:          model.ApplicationStatusNotRunning,
+		}},
+		espressoBlocks: map[common.Address]uint64{validationTestApp: 1450},
+	}
+	s.heads = &fakeChainHeads{l1FinalizedBlock: 100, espressoLatestHeight: 1500}
+	s.service = &EspressoReaderService{
+		statusRepository: s.repository,
+		heads:            s.heads,
+		errors:           NewErrorRecorder(slog.NewTextHandler(io.Discard, nil)),
+	}
+	s.service.readyMaxLag.Store(100)
+	s.service.readerStarted.Store(true)
+	// probes need no credentials, even when the API requires them
+	s.handler = NewHandler(s.service, HttpServerConfig{MaxRequestSize: 1024})
+}
+
+func (s *HealthSuite) get(path string) (int, healthResponse) {
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	var response healthResponse
+	s.Require().Nil(json.Unmarshal(recorder.Body.Bytes(), &response), recorder.Body.String())
+	return recorder.Code, response
+}
+
+// failedChecks returns the errors of the checks that failed, by name
+func failedChecks(response healthResponse) map[string]string {
+	failed := map[string]string{}
+	for _, check := range response.Checks {
+		if !check.Ok {
+			failed[check.Name] = check.Error
+		}
+	}
+	return failed
+}
+
+func (s *HealthSuite) TestItIsAliveWhateverTheDependencies() {
+	s.repository.schemaErr = errors.New("database is down")
+	s.heads.err = errors.New("connection refused")
+	code, response := s.get("/healthz")
+	s.Equal(http.StatusOK, code)
+	s.Equal("ok", response.Status)
+}
+
+func (s *HealthSuite) TestItIsReady() {
+	code, response := s.get("/readyz")
+	s.Equal(http.StatusOK, code)
+	s.Equal("ok", response.Status)
+	s.Len(response.Checks, 5)
+	s.Empty(failedChecks(response))
+}
+
+func (s *HealthSuite) TestItIsNotReadyWithAnInvalidSchema() {
+	s.repository.schemaErr = errors.New("database schema version mismatch")
+	code, response := s.get("/readyz")
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal("unavailable", response.Status)
+	s.Equal(map[string]string{checkDatabase: "database schema version mismatch"}, failedChecks(response))
+}
+
+func (s *HealthSuite) TestItIsNotReadyWithUnreachableHeads() {
+	s.heads.err = errors.New("connection refused")
+	code, response := s.get("/readyz")
+	s.Equal(http.StatusServiceUnavailable, code)
+	failed := failedChecks(response)
+	s.Equal("connection refused", failed[checkL1])
+	s.Equal("connection refused", failed[checkEspresso])
+	s.Contains(failed, checkLag)
+	s.NotContains(failed, checkDatabase)
+}
+
+func (s *HealthSuite) TestItIsNotReadyBeforeTheReaderStarts() {
+	s.service.readerStarted.Store(false)
+	code, response := s.get("/readyz")
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal([]string{checkReader}, keys(failedChecks(response)))
+}
+
+func (s *HealthSuite) TestItIsNotReadyWhenARunningApplicationLags() {
+	s.repository.espressoBlocks[validationTestApp] = 1000
+	code, response := s.get("/readyz")
+	s.Equal(http.StatusServiceUnavailable, code)
+	failed := failedChecks(response)
+	s.Require().Contains(failed, checkLag)
+	s.Contains(failed[checkLag], validationTestApp.Hex())
+	s.Contains(failed[checkLag], "500 Espresso blocks behind")
+}
+
+func (s *HealthSuite) TestItTakesTheAppliedMaxLag() {
+	s.repository.espressoBlocks[validationTestApp] = 1000
+	s.service.logLevel = new(slog.LevelVar)
+	s.service.retryPolicy = retry.NewPolicy(0, 0)
+	s.service.rateLimiter = NewSubmitRateLimiter(RateLimitConfig{})
+	s.service.Apply(Settings{LogLevel: slog.LevelWarn, MaxRetries: 5, ReadyMaxLag: 500})
+
+	code, _ := s.get("/readyz")
+	s.Equal(http.StatusOK, code)
+	s.Equal(slog.LevelWarn, s.service.logLevel.Level())
+	s.Equal(uint64(5), s.service.retryPolicy.MaxRetries())
+}
+
+func (s *HealthSuite) TestItOnlyAppliesTheChangedSettings() {
+	s.service.logLevel = new(slog.LevelVar)
+	s.service.retryPolicy = retry.NewPolicy(0, 0)
+	s.service.rateLimiter = NewSubmitRateLimiter(RateLimitConfig{})
+	s.service.Apply(Settings{LogLevel: slog.LevelWarn, ReadyMaxLag: 500})
+
+	// as the admin endpoint does
+	s.service.logLevel.Set(slog.LevelDebug)
+	s.service.Apply(Settings{LogLevel: slog.LevelWarn, MaxRetries: 5, ReadyMaxLag: 500})
+	s.Equal(slog.LevelDebug, s.service.logLevel.Level())
+	s.Equal(uint64(5), s.service.retryPolicy.MaxRetries())
+
+	s.service.Apply(Settings{LogLevel: slog.LevelError, MaxRetries: 5, ReadyMaxLag: 500})
+	s.Equal(slog.LevelError, s.service.logLevel.Level())
+}
+
+func keys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
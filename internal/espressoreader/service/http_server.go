@@ -0,0 +1,103 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+)
+
+// HttpServerConfig configures the HTTP server of the Espresso service
+type HttpServerConfig struct {
+	Endpoint        string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	ShutdownTimeout time.Duration
+	// Serves HTTPS when both files are set
+	TlsCertFile string
+	TlsKeyFile  string
+	// Origins allowed by CORS, "*" allows any origin
+	CorsAllowedOrigins []string
+	// Proxies whose X-Forwarded-For header tells the client IP
+	TrustedProxies []netip.Prefix
+	MaxRequestSize int64
+	Auth           AuthConfig
+}
+
+func (c HttpServerConfig) tlsEnabled() bool {
+	return c.TlsCertFile != "" || c.TlsKeyFile != ""
+}
+
+func (c HttpServerConfig) validate() error {
+	if c.tlsEnabled() && (c.TlsCertFile == "" || c.TlsKeyFile == "") {
+		return fmt.Errorf("both the TLS certificate and key files must be set to enable TLS")
+	}
+	if c.MaxRequestSize <= 0 {
+		return fmt.Errorf("max request size must be positive, got %d", c.MaxRequestSize)
+	}
+	return nil
+}
+
+// serveHttp serves the Espresso service API until the context is canceled,
+// then waits up to ShutdownTimeout for in-flight requests to finish
+func serveHttp(ctx context.Context, config HttpServerConfig, handler http.Handler) error {
+	if err := config.validate(); err != nil {
+		return fmt.Errorf("invalid espresso service config: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", config.Endpoint)
+	if err != nil {
+		return fmt.Errorf("espresso service failed to listen: %w", err)
+	}
+	return serveHttpOn(ctx, listener, config, handler)
+}
+
+func serveHttpOn(
+	ctx context.Context,
+	listener net.Listener,
+	config HttpServerConfig,
+	handler http.Handler,
+) error {
+	server := &http.Server{
+		Handler:      handler,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		ErrorLog:     slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		slog.Info("espresso service: listening",
+			"address", listener.Addr(), "tls", config.tlsEnabled())
+		if config.tlsEnabled() {
+			served <- server.ServeTLS(listener, config.TlsCertFile, config.TlsKeyFile)
+		} else {
+			served <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("espresso service stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("espresso service: shutting down", "timeout", config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("espresso service failed to shut down gracefully: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("espresso service stopped: %w", err)
+	}
+	return nil
+}
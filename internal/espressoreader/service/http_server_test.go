@@ -0,0 +1,89 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HttpServerSuite struct {
+	suite.Suite
+	config HttpServerConfig
+}
+
+func TestHttpServerSuite(t *testing.T) {
+	suite.Run(t, new(HttpServerSuite))
+}
+
+func (s *HttpServerSuite) SetupTest() {
+	s.config = HttpServerConfig{
+		Endpoint:        "127.0.0.1:0",
+		ReadTimeout:     time.Second,
+		WriteTimeout:    time.Second,
+		ShutdownTimeout: time.Second,
+		MaxRequestSize:  1024,
+	}
+}
+
+func (s *HttpServerSuite) TestItRejectsInvalidConfigs() {
+	s.config.TlsCertFile = "cert.pem"
+	s.Error(serveHttp(context.Background(), s.config, http.NotFoundHandler()))
+
+	s.SetupTest()
+	s.config.MaxRequestSize = 0
+	s.Error(serveHttp(context.Background(), s.config, http.NotFoundHandler()))
+}
+
+func (s *HttpServerSuite) TestItFailsWhenTheCertificateIsMissing() {
+	s.config.TlsCertFile = "missing-cert.pem"
+	s.config.TlsKeyFile = "missing-key.pem"
+	s.Error(serveHttp(context.Background(), s.config, http.NotFoundHandler()))
+}
+
+func (s *HttpServerSuite) TestItShutsDownGracefully() {
+	listener, err := net.Listen("tcp", s.config.Endpoint)
+	s.Require().Nil(err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serveHttpOn(ctx, listener, s.config, handler)
+	}()
+
+	responded := make(chan int, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responded <- 0
+			return
+		}
+		response.Body.Close()
+		responded <- response.StatusCode
+	}()
+
+	<-started
+	cancel()
+	// the in-flight request is allowed to finish
+	close(release)
+	s.Equal(http.StatusOK, <-responded)
+	s.Nil(<-served)
+
+	// and new connections are refused
+	_, err = http.Get("http://" + listener.Addr().String())
+	s.Error(err)
+}
@@ -0,0 +1,46 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var logLevels = []LogLevelLevel{LogLevelLevelDebug, LogLevelLevelInfo, LogLevelLevelWarn, LogLevelLevelError}
+
+// GetLogLevel implements GET /admin/log-level
+func (s *EspressoReaderService) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, LogLevel{Level: LogLevelLevel(strings.ToLower(s.logLevel.Level().String()))})
+}
+
+// SetLogLevel implements PUT /admin/log-level
+func (s *EspressoReaderService) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+	var request LogLevel
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("invalid log level request: %v", err))
+		return
+	}
+	var level slog.Level
+	if !slices.Contains(logLevels, request.Level) || level.UnmarshalText([]byte(request.Level)) != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeBadRequest,
+			fmt.Sprintf("level must be one of debug, info, warn or error, got '%s'", request.Level))
+		return
+	}
+
+	previous := s.logLevel.Level()
+	s.logLevel.Set(level)
+	slog.Warn("espresso service: changed the log level", "from", previous, "to", level)
+	writeJSON(w, http.StatusOK, request)
+}
@@ -0,0 +1,241 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceRepository is the part of the repository the NonceManager reconciles against.
+// The espresso_nonce table holds, for each (sender, app) pair, the next nonce
+// the reader expects to consume.
+type NonceRepository interface {
+	GetEspressoNonce(
+		ctx context.Context,
+		senderAddress common.Address,
+		applicationAddress common.Address,
+	) (uint64, error)
+}
+
+type nonceKey struct {
+	app    common.Address
+	sender common.Address
+}
+
+// nonceEntry keeps track of the nonces of a single (app, sender) pair
+type nonceEntry struct {
+	// next nonce to be consumed by the reader, as last seen in the database
+	confirmed uint64
+	// nonces whose transactions were submitted to Espresso but not yet consumed,
+	// mapped to the time they were submitted
+	pending map[uint64]time.Time
+}
+
+// next returns the first nonce after the contiguous run of pending nonces
+func (e *nonceEntry) next() uint64 {
+	nonce := e.confirmed
+	for {
+		if _, ok := e.pending[nonce]; !ok {
+			return nonce
+		}
+		nonce++
+	}
+}
+
+// expire drops the pending nonces submitted before the deadline.
+// A nonce that never landed blocks every nonce after it,
+// so those are dropped as well.
+func (e *nonceEntry) expire(deadline time.Time) {
+	expired, found := uint64(0), false
+	for nonce, submittedAt := range e.pending {
+		if submittedAt.Before(deadline) && (!found || nonce < expired) {
+			expired, found = nonce, true
+		}
+	}
+	if !found {
+		return
+	}
+	for nonce := range e.pending {
+		if nonce >= expired {
+			delete(e.pending, nonce)
+		}
+	}
+}
+
+// reconcile updates the entry with the nonce read from the database,
+// dropping every pending nonce the reader already consumed
+func (e *nonceEntry) reconcile(confirmed uint64) {
+	if confirmed > e.confirmed {
+		e.confirmed = confirmed
+	}
+	for nonce := range e.pending {
+		if nonce < e.confirmed {
+			delete(e.pending, nonce)
+		}
+	}
+}
+
+// NonceManager hands out Espresso nonces for (app, sender) pairs.
+// It is safe for concurrent use.
+//
+// A submission reserves its nonce with Reserve, which checks and marks the nonce
+// pending atomically, so concurrent submissions of the same nonce can't both be
+// forwarded to Espresso. Reservations are released with Release when the
+// submission fails, and otherwise once the reader consumes them or once
+// pendingTimeout elapses without them landing.
+type NonceManager struct {
+	mu             sync.Mutex
+	repository     NonceRepository
+	pendingTimeout time.Duration
+	entries        map[nonceKey]*nonceEntry
+	now            func() time.Time
+}
+
+func NewNonceManager(repository NonceRepository, pendingTimeout time.Duration) *NonceManager {
+	return &NonceManager{
+		repository:     repository,
+		pendingTimeout: pendingTimeout,
+		entries:        make(map[nonceKey]*nonceEntry),
+		now:            time.Now,
+	}
+}
+
+// Next returns the nonce the sender should use on its next transaction to the app.
+// It is advisory only: another submission may take the nonce before the sender
+// uses it, which Reserve then rejects.
+func (m *NonceManager) Next(
+	ctx context.Context,
+	app common.Address,
+	sender common.Address,
+) (uint64, error) {
+	// query the database outside the lock so a slow query
+	// does not hold back requests from other senders.
+	// The database nonce only grows, so a stale read is harmless.
+	confirmed, err := m.repository.GetEspressoNonce(ctx, sender, app)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.update(nonceKey{app: app, sender: sender}, confirmed).next(), nil
+}
+
+// Reserve marks the nonce pending if it is the next nonce of the sender.
+// Otherwise, it returns the nonce the sender should have used and false.
+func (m *NonceManager) Reserve(
+	ctx context.Context,
+	app common.Address,
+	sender common.Address,
+	nonce uint64,
+) (uint64, bool, error) {
+	confirmed, err := m.repository.GetEspressoNonce(ctx, sender, app)
+	if err != nil {
+		return 0, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.update(nonceKey{app: app, sender: sender}, confirmed)
+	expected := entry.next()
+	if nonce != expected {
+		return expected, false, nil
+	}
+	entry.pending[nonce] = m.now()
+	return nonce, true, nil
+}
+
+// Release drops the reservation of a nonce whose transaction failed to reach Espresso.
+// The nonces reserved after it can't land without it, so they are dropped as well.
+func (m *NonceManager) Release(
+	app common.Address,
+	sender common.Address,
+	nonce uint64,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[nonceKey{app: app, sender: sender}]
+	if !ok {
+		return
+	}
+	for pending := range entry.pending {
+		if pending >= nonce {
+			delete(entry.pending, pending)
+		}
+	}
+}
+
+// Add marks a nonce pending, as if its transaction was submitted to Espresso
+func (m *NonceManager) Add(
+	app common.Address,
+	sender common.Address,
+	nonce uint64,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := m.entry(nonceKey{app: app, sender: sender})
+	if nonce < entry.confirmed {
+		slog.Warn("espresso service: nonce was already consumed",
+			"app", app, "sender", sender, "nonce", nonce)
+		return
+	}
+	entry.pending[nonce] = m.now()
+}
+
+// Prune drops expired pending nonces, and forgets about the pairs left without any
+func (m *NonceManager) Prune() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deadline := m.now().Add(-m.pendingTimeout)
+	for key, entry := range m.entries {
+		entry.expire(deadline)
+		if len(entry.pending) == 0 {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Run prunes the NonceManager periodically until the context is canceled
+func (m *NonceManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.pendingTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Prune()
+		}
+	}
+}
+
+// update reconciles the entry of the given key with the database nonce,
+// and expires its pending nonces. The caller must hold the lock.
+func (m *NonceManager) update(key nonceKey, confirmed uint64) *nonceEntry {
+	entry := m.entry(key)
+	entry.reconcile(confirmed)
+	entry.expire(m.now().Add(-m.pendingTimeout))
+	return entry
+}
+
+// entry returns the entry for the given key, creating it if needed.
+// The caller must hold the lock.
+func (m *NonceManager) entry(key nonceKey) *nonceEntry {
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &nonceEntry{pending: make(map[uint64]time.Time)}
+		m.entries[key] = entry
+	}
+	return entry
+}
@@ -0,0 +1,278 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	nonceTestApp    = common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+	nonceTestSender = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+)
+
+type NonceManagerSuite struct {
+	suite.Suite
+	ctx        context.Context
+	repository *FakeNonceRepository
+	manager    *NonceManager
+	clock      time.Time
+}
+
+func TestNonceManagerSuite(t *testing.T) {
+	suite.Run(t, new(NonceManagerSuite))
+}
+
+func (s *NonceManagerSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.repository = newFakeNonceRepository()
+	s.manager = NewNonceManager(s.repository, time.Minute)
+	s.clock = time.Unix(1700000000, 0)
+	s.manager.now = func() time.Time { return s.clock }
+}
+
+func (s *NonceManagerSuite) next() uint64 {
+	nonce, err := s.manager.Next(s.ctx, nonceTestApp, nonceTestSender)
+	s.Require().Nil(err)
+	return nonce
+}
+
+func (s *NonceManagerSuite) TestItStartsFromTheDatabaseNonce() {
+	s.repository.set(nonceTestApp, nonceTestSender, 7)
+	s.Equal(uint64(7), s.next())
+	s.Equal(uint64(7), s.next())
+}
+
+func (s *NonceManagerSuite) TestItSkipsPendingNonces() {
+	s.Equal(uint64(0), s.next())
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+	s.Equal(uint64(1), s.next())
+	s.manager.Add(nonceTestApp, nonceTestSender, 1)
+	s.Equal(uint64(2), s.next())
+}
+
+func (s *NonceManagerSuite) TestItDoesNotAdvanceWithoutSubmission() {
+	s.Equal(uint64(0), s.next())
+	// the transaction failed to reach Espresso, so Add was never called
+	s.Equal(uint64(0), s.next())
+}
+
+func (s *NonceManagerSuite) TestItReconcilesWithTheDatabase() {
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+	s.manager.Add(nonceTestApp, nonceTestSender, 1)
+	s.Equal(uint64(2), s.next())
+
+	// the reader consumed both nonces
+	s.repository.set(nonceTestApp, nonceTestSender, 2)
+	s.Equal(uint64(2), s.next())
+	s.Empty(s.manager.entries[nonceKey{app: nonceTestApp, sender: nonceTestSender}].pending)
+}
+
+func (s *NonceManagerSuite) TestItJumpsToNoncesConsumedElsewhere() {
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+	s.manager.Add(nonceTestApp, nonceTestSender, 1)
+
+	// a third party submitted nonces 0 to 4 directly to Espresso, and the reader consumed them
+	s.repository.set(nonceTestApp, nonceTestSender, 5)
+	s.Equal(uint64(5), s.next())
+	s.Empty(s.manager.entries[nonceKey{app: nonceTestApp, sender: nonceTestSender}].pending)
+}
+
+func (s *NonceManagerSuite) reserve(nonce uint64) (uint64, bool) {
+	expected, reserved, err := s.manager.Reserve(s.ctx, nonceTestApp, nonceTestSender, nonce)
+	s.Require().Nil(err)
+	return expected, reserved
+}
+
+func (s *NonceManagerSuite) TestItReservesTheNextNonceOnce() {
+	expected, reserved := s.reserve(0)
+	s.True(reserved)
+	s.Equal(uint64(0), expected)
+
+	expected, reserved = s.reserve(0)
+	s.False(reserved)
+	s.Equal(uint64(1), expected)
+
+	expected, reserved = s.reserve(2)
+	s.False(reserved)
+	s.Equal(uint64(1), expected)
+	s.Equal(uint64(1), s.next())
+}
+
+func (s *NonceManagerSuite) TestItReleasesReservedNonces() {
+	_, reserved := s.reserve(0)
+	s.Require().True(reserved)
+	_, reserved = s.reserve(1)
+	s.Require().True(reserved)
+
+	// nonce 1 can't land without nonce 0
+	s.manager.Release(nonceTestApp, nonceTestSender, 0)
+	s.Equal(uint64(0), s.next())
+	_, reserved = s.reserve(0)
+	s.True(reserved)
+}
+
+func (s *NonceManagerSuite) TestItFailsToReserveWhenTheDatabaseFails() {
+	s.repository.err = errors.New("connection refused")
+	_, _, err := s.manager.Reserve(s.ctx, nonceTestApp, nonceTestSender, 0)
+	s.ErrorIs(err, s.repository.err)
+}
+
+func (s *NonceManagerSuite) TestItIgnoresConsumedNonces() {
+	s.repository.set(nonceTestApp, nonceTestSender, 3)
+	s.Equal(uint64(3), s.next())
+	s.manager.Add(nonceTestApp, nonceTestSender, 1)
+	s.Equal(uint64(3), s.next())
+}
+
+func (s *NonceManagerSuite) TestItExpiresPendingNonces() {
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+	s.clock = s.clock.Add(30 * time.Second)
+	s.manager.Add(nonceTestApp, nonceTestSender, 1)
+	s.Equal(uint64(2), s.next())
+
+	// nonce 0 never landed, so nonce 1 can never land either
+	s.clock = s.clock.Add(31 * time.Second)
+	s.Equal(uint64(0), s.next())
+}
+
+func (s *NonceManagerSuite) TestItKeepsPendingNoncesWhileTheyMayLand() {
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+	s.clock = s.clock.Add(59 * time.Second)
+	s.Equal(uint64(1), s.next())
+}
+
+func (s *NonceManagerSuite) TestItTracksPairsIndependently() {
+	otherApp := common.HexToAddress("0xdeadbeef")
+	otherSender := common.HexToAddress("0xfeedbeef")
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+
+	nonce, err := s.manager.Next(s.ctx, otherApp, nonceTestSender)
+	s.Require().Nil(err)
+	s.Equal(uint64(0), nonce)
+	nonce, err = s.manager.Next(s.ctx, nonceTestApp, otherSender)
+	s.Require().Nil(err)
+	s.Equal(uint64(0), nonce)
+	s.Equal(uint64(1), s.next())
+}
+
+func (s *NonceManagerSuite) TestItFailsWhenTheDatabaseFails() {
+	s.repository.err = errors.New("connection refused")
+	_, err := s.manager.Next(s.ctx, nonceTestApp, nonceTestSender)
+	s.ErrorIs(err, s.repository.err)
+}
+
+func (s *NonceManagerSuite) TestPrune() {
+	s.manager.Add(nonceTestApp, nonceTestSender, 0)
+	s.manager.Prune()
+	s.Len(s.manager.entries, 1)
+
+	s.clock = s.clock.Add(2 * time.Minute)
+	s.manager.Prune()
+	s.Empty(s.manager.entries)
+}
+
+// Meant to be run with the race detector (go test -race)
+func (s *NonceManagerSuite) TestConcurrentUse() {
+	manager := NewNonceManager(s.repository, time.Minute)
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go manager.Run(ctx)
+
+	const workers = 16
+	const submissions = 50
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(sender common.Address) {
+			defer wg.Done()
+			for j := 0; j < submissions; j++ {
+				nonce, err := manager.Next(ctx, nonceTestApp, sender)
+				s.Nil(err)
+				s.Equal(uint64(j), nonce)
+				manager.Add(nonceTestApp, sender, nonce)
+				manager.Prune()
+			}
+		}(common.BigToAddress(big.NewInt(int64(i + 1))))
+	}
+	// concurrent submissions from the same sender never move the nonce backwards
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < submissions; j++ {
+				nonce, err := manager.Next(ctx, nonceTestApp, nonceTestSender)
+				s.Nil(err)
+				manager.Add(nonceTestApp, nonceTestSender, nonce)
+			}
+		}()
+	}
+	wg.Wait()
+
+	nonce, err := manager.Next(ctx, nonceTestApp, nonceTestSender)
+	s.Require().Nil(err)
+	s.GreaterOrEqual(nonce, uint64(submissions))
+	s.LessOrEqual(nonce, uint64(workers*submissions))
+}
+
+// Meant to be run with the race detector (go test -race)
+func (s *NonceManagerSuite) TestConcurrentReservationsOfANonce() {
+	const workers = 16
+	var (
+		wg       sync.WaitGroup
+		reserved atomic.Int32
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := s.manager.Reserve(s.ctx, nonceTestApp, nonceTestSender, 0)
+			s.Nil(err)
+			if ok {
+				reserved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	s.Equal(int32(1), reserved.Load())
+}
+
+// FakeNonceRepository is an in-memory NonceRepository safe for concurrent use
+type FakeNonceRepository struct {
+	mu     sync.Mutex
+	nonces map[nonceKey]uint64
+	err    error
+}
+
+func newFakeNonceRepository() *FakeNonceRepository {
+	return &FakeNonceRepository{nonces: make(map[nonceKey]uint64)}
+}
+
+func (r *FakeNonceRepository) set(app common.Address, sender common.Address, nonce uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nonces[nonceKey{app: app, sender: sender}] = nonce
+}
+
+func (r *FakeNonceRepository) GetEspressoNonce(
+	ctx context.Context,
+	senderAddress common.Address,
+	applicationAddress common.Address,
+) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.nonces[nonceKey{app: applicationAddress, sender: senderAddress}], nil
+}
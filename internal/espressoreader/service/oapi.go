@@ -0,0 +1,681 @@
+// Package service provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen/v2 version v2.1.0 DO NOT EDIT.
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	ApiKeyScopes = "apiKey.Scopes"
+	HmacScopes   = "hmac.Scopes"
+)
+
+// Defines values for ApplicationInfoStatus.
+const (
+	ApplicationInfoStatusNOTRUNNING ApplicationInfoStatus = "NOT RUNNING"
+	ApplicationInfoStatusRUNNING    ApplicationInfoStatus = "RUNNING"
+)
+
+// Defines values for ApplicationSyncStatusStatus.
+const (
+	ApplicationSyncStatusStatusNOTRUNNING ApplicationSyncStatusStatus = "NOT RUNNING"
+	ApplicationSyncStatusStatusRUNNING    ApplicationSyncStatusStatus = "RUNNING"
+)
+
+// Defines values for LogLevelLevel.
+const (
+	LogLevelLevelDebug LogLevelLevel = "debug"
+	LogLevelLevelError LogLevelLevel = "error"
+	LogLevelLevelInfo  LogLevelLevel = "info"
+	LogLevelLevelWarn  LogLevelLevel = "warn"
+)
+
+// Defines values for TransactionStatusStatus.
+const (
+	INGESTED TransactionStatusStatus = "INGESTED"
+	UNKNOWN  TransactionStatusStatus = "UNKNOWN"
+)
+
+// Address Hex-encoded 20-byte address.
+type Address = string
+
+// ApplicationInfo defines model for ApplicationInfo.
+type ApplicationInfo struct {
+	// ContractAddress Hex-encoded 20-byte address.
+	ContractAddress Address `json:"contract_address"`
+
+	// IconsensusAddress Hex-encoded 20-byte address.
+	IconsensusAddress          Address               `json:"iconsensus_address"`
+	LastProcessedBlock         uint64                `json:"last_processed_block"`
+	LastProcessedEspressoBlock uint64                `json:"last_processed_espresso_block"`
+	NextInputIndex             uint64                `json:"next_input_index"`
+	Status                     ApplicationInfoStatus `json:"status"`
+
+	// TemplateHash Hex-encoded 32-byte hash.
+	TemplateHash Hash `json:"template_hash"`
+}
+
+// ApplicationInfoStatus defines model for ApplicationInfo.Status.
+type ApplicationInfoStatus string
+
+// ApplicationSyncStatus defines model for ApplicationSyncStatus.
+type ApplicationSyncStatus struct {
+	// ContractAddress Hex-encoded 20-byte address.
+	ContractAddress Address `json:"contract_address"`
+
+	// EspressoLag Espresso blocks after `last_processed_espresso_block`.
+	EspressoLag *uint64 `json:"espresso_lag,omitempty"`
+
+	// L1Lag Finalized blocks after `last_processed_block`.
+	L1Lag               *uint64 `json:"l1_lag,omitempty"`
+	LastClaimCheckBlock uint64  `json:"last_claim_check_block"`
+
+	// LastError Error logged by the reader.
+	LastError                  *ErrorRecord                `json:"last_error,omitempty"`
+	LastOutputCheckBlock       uint64                      `json:"last_output_check_block"`
+	LastProcessedBlock         uint64                      `json:"last_processed_block"`
+	LastProcessedEspressoBlock uint64                      `json:"last_processed_espresso_block"`
+	NextInputIndex             uint64                      `json:"next_input_index"`
+	OpenEpoch                  *Epoch                      `json:"open_epoch,omitempty"`
+	Status                     ApplicationSyncStatusStatus `json:"status"`
+}
+
+// ApplicationSyncStatusStatus defines model for ApplicationSyncStatus.Status.
+type ApplicationSyncStatusStatus string
+
+// DomainInfo defines model for DomainInfo.
+type DomainInfo struct {
+	// App Hex-encoded 20-byte address.
+	App     Address         `json:"app"`
+	ChainId uint64          `json:"chain_id"`
+	Domain  TypedDataDomain `json:"domain"`
+
+	// MaxPayloadSize Maximum size, in bytes, of the `data` field of the message.
+	MaxPayloadSize uint64 `json:"max_payload_size"`
+
+	// Namespace Espresso namespace the inputs are sequenced in.
+	Namespace   uint64 `json:"namespace"`
+	PrimaryType string `json:"primaryType"`
+
+	// Types EIP-712 types, including `EIP712Domain`.
+	Types map[string][]TypedDataField `json:"types"`
+}
+
+// Epoch defines model for Epoch.
+type Epoch struct {
+	FirstBlock uint64 `json:"first_block"`
+	Index      uint64 `json:"index"`
+	LastBlock  uint64 `json:"last_block"`
+}
+
+// ErrorRecord Error logged by the reader.
+type ErrorRecord struct {
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	// Code Machine-readable error code, such as `invalid_nonce`.
+	Code string `json:"code"`
+
+	// Message Human-readable description of the error.
+	Message string `json:"message"`
+}
+
+// Hash Hex-encoded 32-byte hash.
+type Hash = string
+
+// Input defines model for Input.
+type Input struct {
+	// AppContract Hex-encoded 20-byte address.
+	AppContract Address `json:"app_contract"`
+	BlockNumber uint64  `json:"block_number"`
+	Index       uint64  `json:"index"`
+
+	// Status Completion status of the input.
+	Status string `json:"status"`
+}
+
+// LogLevel defines model for LogLevel.
+type LogLevel struct {
+	Level LogLevelLevel `json:"level"`
+}
+
+// LogLevelLevel defines model for LogLevel.Level.
+type LogLevelLevel string
+
+// NonceRequest defines model for NonceRequest.
+type NonceRequest struct {
+	// AppContract Hex-encoded 20-byte address.
+	AppContract Address `json:"app_contract"`
+
+	// MsgSender Hex-encoded 20-byte address.
+	MsgSender Address `json:"msg_sender"`
+}
+
+// NonceResponse defines model for NonceResponse.
+type NonceResponse struct {
+	Nonce uint64 `json:"nonce"`
+}
+
+// SigAndData EIP-712 typed data of a CartesiMessage and its signature.
+type SigAndData struct {
+	// Account Hex-encoded 20-byte address.
+	Account *Address `json:"account,omitempty"`
+
+	// Signature Hex-encoded 65-byte signature of the typed data.
+	Signature string `json:"signature"`
+
+	// TypedData EIP-712 typed data, as accepted by `eth_signTypedData_v4`.
+	TypedData map[string]interface{} `json:"typedData"`
+}
+
+// SubmitResponse defines model for SubmitResponse.
+type SubmitResponse struct {
+	// Id Hex-encoded 32-byte hash.
+	Id Hash `json:"id"`
+}
+
+// SyncStatus defines model for SyncStatus.
+type SyncStatus struct {
+	Applications []ApplicationSyncStatus `json:"applications"`
+
+	// EspressoLatestHeight Latest block height of Espresso.
+	EspressoLatestHeight *uint64 `json:"espresso_latest_height,omitempty"`
+
+	// L1FinalizedBlock Latest finalized block of the base layer.
+	L1FinalizedBlock *uint64 `json:"l1_finalized_block,omitempty"`
+
+	// LastError Error logged by the reader.
+	LastError *ErrorRecord `json:"last_error,omitempty"`
+}
+
+// TransactionStatus defines model for TransactionStatus.
+type TransactionStatus struct {
+	// Id Hex-encoded 32-byte hash.
+	Id    Hash   `json:"id"`
+	Input *Input `json:"input,omitempty"`
+
+	// Status `UNKNOWN` until the reader ingests the input.
+	// Inputs that are never sequenced by Espresso stay `UNKNOWN`.
+	Status TransactionStatusStatus `json:"status"`
+}
+
+// TransactionStatusStatus `UNKNOWN` until the reader ingests the input.
+// Inputs that are never sequenced by Espresso stay `UNKNOWN`.
+type TransactionStatusStatus string
+
+// TypedDataDomain defines model for TypedDataDomain.
+type TypedDataDomain struct {
+	ChainId uint64 `json:"chainId"`
+	Name    string `json:"name"`
+
+	// VerifyingContract Hex-encoded 20-byte address.
+	VerifyingContract Address `json:"verifyingContract"`
+	Version           string  `json:"version"`
+}
+
+// TypedDataField defines model for TypedDataField.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ApplicationAddress Hex-encoded 20-byte address.
+type ApplicationAddress = Address
+
+// TransactionId Hex-encoded 32-byte hash.
+type TransactionId = Hash
+
+// Error defines model for Error.
+type Error = ErrorResponse
+
+// TooManyRequests defines model for TooManyRequests.
+type TooManyRequests = ErrorResponse
+
+// GetDomainParams defines parameters for GetDomain.
+type GetDomainParams struct {
+	App Address `form:"app" json:"app"`
+}
+
+// SetLogLevelJSONRequestBody defines body for SetLogLevel for application/json ContentType.
+type SetLogLevelJSONRequestBody = LogLevel
+
+// RequestNonceJSONRequestBody defines body for RequestNonce for application/json ContentType.
+type RequestNonceJSONRequestBody = NonceRequest
+
+// SubmitTransactionJSONRequestBody defines body for SubmitTransaction for application/json ContentType.
+type SubmitTransactionJSONRequestBody = SigAndData
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Current log level
+	// (GET /admin/log-level)
+	GetLogLevel(w http.ResponseWriter, r *http.Request)
+	// Change the log level
+	// (PUT /admin/log-level)
+	SetLogLevel(w http.ResponseWriter, r *http.Request)
+	// Application information
+	// (GET /applications/{address})
+	GetApplication(w http.ResponseWriter, r *http.Request, address ApplicationAddress)
+	// EIP-712 domain and types of the inputs of an application
+	// (GET /domain)
+	GetDomain(w http.ResponseWriter, r *http.Request, params GetDomainParams)
+	// Nonce for the next input of a sender
+	// (POST /nonce)
+	RequestNonce(w http.ResponseWriter, r *http.Request)
+	// Sync status of the reader
+	// (GET /status)
+	GetStatus(w http.ResponseWriter, r *http.Request)
+	// Submit a signed input
+	// (POST /submit)
+	SubmitTransaction(w http.ResponseWriter, r *http.Request)
+	// Status of a submitted input
+	// (GET /transactions/{id})
+	GetTransactionStatus(w http.ResponseWriter, r *http.Request, id TransactionId)
+}
+
+// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+
+type Unimplemented struct{}
+
+// Current log level
+// (GET /admin/log-level)
+func (_ Unimplemented) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Change the log level
+// (PUT /admin/log-level)
+func (_ Unimplemented) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Application information
+// (GET /applications/{address})
+func (_ Unimplemented) GetApplication(w http.ResponseWriter, r *http.Request, address ApplicationAddress) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// EIP-712 domain and types of the inputs of an application
+// (GET /domain)
+func (_ Unimplemented) GetDomain(w http.ResponseWriter, r *http.Request, params GetDomainParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Nonce for the next input of a sender
+// (POST /nonce)
+func (_ Unimplemented) RequestNonce(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Sync status of the reader
+// (GET /status)
+func (_ Unimplemented) GetStatus(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Submit a signed input
+// (POST /submit)
+func (_ Unimplemented) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Status of a submitted input
+// (GET /transactions/{id})
+func (_ Unimplemented) GetTransactionStatus(w http.ResponseWriter, r *http.Request, id TransactionId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// GetLogLevel operation middleware
+func (siw *ServerInterfaceWrapper) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"admin"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetLogLevel(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// SetLogLevel operation middleware
+func (siw *ServerInterfaceWrapper) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"admin"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"admin"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SetLogLevel(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApplication operation middleware
+func (siw *ServerInterfaceWrapper) GetApplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "address" -------------
+	var address ApplicationAddress
+
+	err = runtime.BindStyledParameterWithOptions("simple", "address", chi.URLParam(r, "address"), &address, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "address", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"read"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"read"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApplication(w, r, address)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetDomain operation middleware
+func (siw *ServerInterfaceWrapper) GetDomain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"read"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"read"})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDomainParams
+
+	// ------------- Required query parameter "app" -------------
+
+	if paramValue := r.URL.Query().Get("app"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "app"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "app", r.URL.Query(), &params.App)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "app", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDomain(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequestNonce operation middleware
+func (siw *ServerInterfaceWrapper) RequestNonce(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"submit"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"submit"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RequestNonce(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"read"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"read"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetStatus(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// SubmitTransaction operation middleware
+func (siw *ServerInterfaceWrapper) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"submit"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"submit"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SubmitTransaction(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetTransactionStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetTransactionStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id TransactionId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{"read"})
+
+	ctx = context.WithValue(ctx, HmacScopes, []string{"read"})
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTransactionStatus(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{})
+}
+
+type ChiServerOptions struct {
+	BaseURL          string
+	BaseRouter       chi.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = chi.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/log-level", wrapper.GetLogLevel)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/admin/log-level", wrapper.SetLogLevel)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/applications/{address}", wrapper.GetApplication)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/domain", wrapper.GetDomain)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/nonce", wrapper.RequestNonce)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/status", wrapper.GetStatus)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/submit", wrapper.SubmitTransaction)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/transactions/{id}", wrapper.GetTransactionStatus)
+	})
+
+	return r
+}
@@ -0,0 +1,165 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is a token bucket refilled at PerMinute tokens per minute,
+// holding at most Burst tokens. A zero PerMinute disables the limit.
+type RateLimit struct {
+	PerMinute uint64
+	Burst     uint64
+}
+
+func (l RateLimit) enabled() bool {
+	return l.PerMinute > 0
+}
+
+// RateLimitConfig configures the rate limits of the /submit endpoint
+type RateLimitConfig struct {
+	PerIp     RateLimit
+	PerSender RateLimit
+	PerApp    RateLimit
+}
+
+// keyedLimiter keeps one token bucket per key
+type keyedLimiter[K comparable] struct {
+	limit    RateLimit
+	limiters map[K]*rate.Limiter
+}
+
+func newKeyedLimiter[K comparable](limit RateLimit) *keyedLimiter[K] {
+	return &keyedLimiter[K]{limit: limit, limiters: make(map[K]*rate.Limiter)}
+}
+
+// reserve takes a token from the bucket of the key.
+// If there is none, it returns how long until there is one.
+func (l *keyedLimiter[K]) reserve(key K, now time.Time) (*rate.Reservation, time.Duration) {
+	if !l.limit.enabled() {
+		return nil, 0
+	}
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limit := rate.Limit(float64(l.limit.PerMinute) / time.Minute.Seconds())
+		limiter = rate.NewLimiter(limit, int(max(l.limit.Burst, 1)))
+		l.limiters[key] = limiter
+	}
+
+	reservation := limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return nil, delay
+	}
+	return reservation, 0
+}
+
+// prune forgets about the keys whose buckets are full again
+func (l *keyedLimiter[K]) prune(now time.Time) {
+	for key, limiter := range l.limiters {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(l.limiters, key)
+		}
+	}
+}
+
+// SubmitRateLimiter throttles submissions per client IP, per signer and per application.
+// It is safe for concurrent use.
+type SubmitRateLimiter struct {
+	mu        sync.Mutex
+	perIp     *keyedLimiter[string]
+	perSender *keyedLimiter[common.Address]
+	perApp    *keyedLimiter[common.Address]
+	now       func() time.Time
+}
+
+func NewSubmitRateLimiter(config RateLimitConfig) *SubmitRateLimiter {
+	return &SubmitRateLimiter{
+		perIp:     newKeyedLimiter[string](config.PerIp),
+		perSender: newKeyedLimiter[common.Address](config.PerSender),
+		perApp:    newKeyedLimiter[common.Address](config.PerApp),
+		now:       time.Now,
+	}
+}
+
+// SetConfig changes the rate limits.
+// The buckets of a changed limit start over full, under the new limit,
+// while the others keep their tokens.
+func (l *SubmitRateLimiter) SetConfig(config RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perIp.limit != config.PerIp {
+		l.perIp = newKeyedLimiter[string](config.PerIp)
+	}
+	if l.perSender.limit != config.PerSender {
+		l.perSender = newKeyedLimiter[common.Address](config.PerSender)
+	}
+	if l.perApp.limit != config.PerApp {
+		l.perApp = newKeyedLimiter[common.Address](config.PerApp)
+	}
+}
+
+// AllowIp takes a token from the bucket of the IP address.
+// When throttled, it returns false and how long the client should wait.
+func (l *SubmitRateLimiter) AllowIp(ip string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, retryAfter := l.perIp.reserve(ip, l.now())
+	return retryAfter == 0, retryAfter
+}
+
+// AllowSubmission takes a token from the buckets of both the sender and the application.
+// No token is taken unless both buckets have one.
+// When throttled, it returns false and how long the client should wait.
+func (l *SubmitRateLimiter) AllowSubmission(sender common.Address, app common.Address) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	senderReservation, retryAfter := l.perSender.reserve(sender, now)
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+	_, retryAfter = l.perApp.reserve(app, now)
+	if retryAfter > 0 {
+		if senderReservation != nil {
+			senderReservation.CancelAt(now)
+		}
+		return false, retryAfter
+	}
+	return true, 0
+}
+
+// Prune forgets about the clients that are no longer throttled
+func (l *SubmitRateLimiter) Prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.perIp.prune(now)
+	l.perSender.prune(now)
+	l.perApp.prune(now)
+}
+
+// Run prunes the SubmitRateLimiter periodically until the context is canceled
+func (l *SubmitRateLimiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Prune()
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	rateTestSender      = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	rateTestOtherSender = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	rateTestApp         = common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+)
+
+type RateLimiterSuite struct {
+	suite.Suite
+	limiter *SubmitRateLimiter
+	clock   time.Time
+}
+
+func TestRateLimiterSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterSuite))
+}
+
+func (s *RateLimiterSuite) SetupTest() {
+	s.limiter = NewSubmitRateLimiter(RateLimitConfig{
+		PerIp:     RateLimit{PerMinute: 60, Burst: 2},
+		PerSender: RateLimit{PerMinute: 6, Burst: 1},
+		PerApp:    RateLimit{PerMinute: 60, Burst: 2},
+	})
+	s.clock = time.Unix(1700000000, 0)
+	s.limiter.now = func() time.Time { return s.clock }
+}
+
+func (s *RateLimiterSuite) TestItAllowsBurstsPerIp() {
+	for i := 0; i < 2; i++ {
+		ok, _ := s.limiter.AllowIp("10.0.0.1")
+		s.True(ok)
+	}
+	ok, retryAfter := s.limiter.AllowIp("10.0.0.1")
+	s.False(ok)
+	s.Equal(time.Second, retryAfter)
+
+	// other clients are not affected
+	ok, _ = s.limiter.AllowIp("10.0.0.2")
+	s.True(ok)
+
+	s.clock = s.clock.Add(time.Second)
+	ok, _ = s.limiter.AllowIp("10.0.0.1")
+	s.True(ok)
+}
+
+func (s *RateLimiterSuite) TestItLimitsSenders() {
+	ok, _ := s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.True(ok)
+	ok, retryAfter := s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.False(ok)
+	s.Equal(10*time.Second, retryAfter)
+}
+
+func (s *RateLimiterSuite) TestItLimitsApplications() {
+	thirdSender := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	for _, sender := range []common.Address{rateTestSender, rateTestOtherSender} {
+		ok, _ := s.limiter.AllowSubmission(sender, rateTestApp)
+		s.True(ok)
+	}
+	ok, _ := s.limiter.AllowSubmission(thirdSender, rateTestApp)
+	s.False(ok)
+
+	// the throttled submission did not use up the token of its sender
+	s.clock = s.clock.Add(time.Second)
+	ok, _ = s.limiter.AllowSubmission(thirdSender, rateTestApp)
+	s.True(ok)
+}
+
+func (s *RateLimiterSuite) TestItCanBeDisabled() {
+	limiter := NewSubmitRateLimiter(RateLimitConfig{})
+	for i := 0; i < 100; i++ {
+		ok, _ := limiter.AllowIp("10.0.0.1")
+		s.True(ok)
+		ok, _ = limiter.AllowSubmission(rateTestSender, rateTestApp)
+		s.True(ok)
+	}
+	s.Empty(limiter.perIp.limiters)
+}
+
+func (s *RateLimiterSuite) TestPrune() {
+	s.limiter.AllowIp("10.0.0.1")
+	s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.limiter.Prune()
+	s.Len(s.limiter.perIp.limiters, 1)
+	s.Len(s.limiter.perSender.limiters, 1)
+
+	s.clock = s.clock.Add(time.Minute)
+	s.limiter.Prune()
+	s.Empty(s.limiter.perIp.limiters)
+	s.Empty(s.limiter.perSender.limiters)
+	s.Empty(s.limiter.perApp.limiters)
+}
+
+// Meant to be run with the race detector (go test -race)
+func (s *RateLimiterSuite) TestConcurrentUse() {
+	limiter := NewSubmitRateLimiter(RateLimitConfig{
+		PerIp: RateLimit{PerMinute: 1, Burst: 10},
+	})
+	var wg sync.WaitGroup
+	allowed := make(chan struct{}, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := limiter.AllowIp("10.0.0.1"); ok {
+				allowed <- struct{}{}
+			}
+			limiter.AllowSubmission(rateTestSender, rateTestApp)
+			limiter.Prune()
+		}()
+	}
+	wg.Wait()
+	s.Len(allowed, 10)
+}
+
+func (s *RateLimiterSuite) TestItChangesTheLimits() {
+	ok, _ := s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.True(ok)
+	ok, _ = s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.False(ok)
+
+	s.limiter.SetConfig(RateLimitConfig{
+		PerIp:     RateLimit{PerMinute: 60, Burst: 2},
+		PerSender: RateLimit{PerMinute: 60, Burst: 3},
+		PerApp:    RateLimit{PerMinute: 60, Burst: 3},
+	})
+	for i := 0; i < 3; i++ {
+		ok, _ = s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+		s.True(ok)
+	}
+	ok, retryAfter := s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.False(ok)
+	s.Equal(time.Second, retryAfter)
+}
+
+func (s *RateLimiterSuite) TestItKeepsTheBucketsOfUnchangedLimits() {
+	ok, _ := s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.True(ok)
+
+	// reloading the same limits does not refill the buckets
+	s.limiter.SetConfig(RateLimitConfig{
+		PerIp:     RateLimit{PerMinute: 60, Burst: 2},
+		PerSender: RateLimit{PerMinute: 6, Burst: 1},
+		PerApp:    RateLimit{PerMinute: 60, Burst: 2},
+	})
+	ok, retryAfter := s.limiter.AllowSubmission(rateTestSender, rateTestApp)
+	s.False(ok)
+	s.Equal(10*time.Second, retryAfter)
+}
@@ -0,0 +1,49 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Settings are the settings of the service that can change while it runs
+type Settings struct {
+	LogLevel    slog.Level
+	MaxRetries  uint64
+	MaxDelay    time.Duration
+	RateLimits  RateLimitConfig
+	ReadyMaxLag uint64
+}
+
+// Apply changes the settings of the running service that differ from the last applied ones,
+// so a reload keeps, for instance, a log level set through the admin endpoint
+// unless the configured log level changed too.
+// The retry policy applies to the calls that start after it.
+func (s *EspressoReaderService) Apply(settings Settings) {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+	previous := s.settings
+	if settings == previous {
+		return
+	}
+	if settings.LogLevel != previous.LogLevel {
+		s.logLevel.Set(settings.LogLevel)
+	}
+	if settings.MaxRetries != previous.MaxRetries || settings.MaxDelay != previous.MaxDelay {
+		s.retryPolicy.Set(settings.MaxRetries, settings.MaxDelay)
+	}
+	if settings.RateLimits != previous.RateLimits {
+		s.rateLimiter.SetConfig(settings.RateLimits)
+	}
+	if settings.ReadyMaxLag != previous.ReadyMaxLag {
+		s.readyMaxLag.Store(settings.ReadyMaxLag)
+	}
+	s.settings = settings
+	slog.Info("espresso service: applied the new settings",
+		"log_level", settings.LogLevel,
+		"max_retries", settings.MaxRetries,
+		"max_delay", settings.MaxDelay,
+		"ready_max_lag", settings.ReadyMaxLag)
+}
@@ -0,0 +1,185 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader"
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/repository"
+
+	"github.com/EspressoSystems/espresso-sequencer-go/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// How long the status endpoint waits for the blockchain and Espresso heads
+const statusHeadsTimeout = 5 * time.Second
+
+// StatusRepository is the part of the repository the status and readiness endpoints read
+type StatusRepository interface {
+	GetAllApplications(ctx context.Context) ([]model.Application, error)
+	GetLastProcessedEspressoBlock(ctx context.Context, app common.Address) (uint64, error)
+	GetInputIndex(ctx context.Context, app common.Address) (uint64, error)
+	GetOpenEpoch(ctx context.Context, app common.Address) (*model.Epoch, error)
+	CheckSchema(ctx context.Context) error
+}
+
+// ChainHeads reads the heads the applications are behind of
+type ChainHeads interface {
+	L1FinalizedBlock(ctx context.Context) (uint64, error)
+	EspressoLatestHeight(ctx context.Context) (uint64, error)
+	// Close releases the connections to the chains
+	Close()
+}
+
+// statusDatabase adds the Espresso cursor to the queries of the repository
+type statusDatabase struct {
+	*repository.Database
+}
+
+func (d statusDatabase) GetLastProcessedEspressoBlock(
+	ctx context.Context,
+	app common.Address,
+) (uint64, error) {
+	return espressoreader.GetLastProcessedEspressoBlock(ctx, d.Database, app)
+}
+
+// chainHeads reads the heads from the blockchain HTTP endpoint and the Espresso API
+type chainHeads struct {
+	l1       *ethclient.Client
+	espresso *client.Client
+}
+
+// dialChainHeads dials the blockchain once, so the probes reuse its connections
+func dialChainHeads(blockchainHttpEndpoint string, espressoBaseUrl string) (*chainHeads, error) {
+	l1, err := ethclient.Dial(blockchainHttpEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the blockchain HTTP endpoint: %w", err)
+	}
+	return &chainHeads{l1: l1, espresso: client.NewClient(espressoBaseUrl)}, nil
+}
+
+func (h *chainHeads) L1FinalizedBlock(ctx context.Context) (uint64, error) {
+	header, err := h.l1.HeaderByNumber(ctx, big.NewInt(rpc.FinalizedBlockNumber.Int64()))
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (h *chainHeads) EspressoLatestHeight(ctx context.Context) (uint64, error) {
+	return h.espresso.FetchLatestBlockHeight(ctx)
+}
+
+func (h *chainHeads) Close() {
+	h.l1.Close()
+}
+
+// GetStatus implements GET /status
+func (s *EspressoReaderService) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.syncStatus(r.Context())
+	if err != nil {
+		slog.Error("failed to get sync status", "err", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to get status")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *EspressoReaderService) syncStatus(ctx context.Context) (*SyncStatus, error) {
+	status := SyncStatus{Applications: []ApplicationSyncStatus{}}
+
+	headsCtx, cancel := context.WithTimeout(ctx, statusHeadsTimeout)
+	defer cancel()
+	l1FinalizedBlock, err := s.heads.L1FinalizedBlock(headsCtx)
+	if err != nil {
+		slog.Warn("status: failed to get the L1 finalized block", "error", err)
+	} else {
+		status.L1FinalizedBlock = &l1FinalizedBlock
+	}
+	espressoLatestHeight, err := s.heads.EspressoLatestHeight(headsCtx)
+	if err != nil {
+		slog.Warn("status: failed to get the latest Espresso height", "error", err)
+	} else {
+		status.EspressoLatestHeight = &espressoLatestHeight
+	}
+	status.LastError = s.errors.Last()
+
+	apps, err := s.statusRepository.GetAllApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range apps {
+		appStatus, err := s.applicationSyncStatus(ctx, &apps[i], &status)
+		if err != nil {
+			return nil, fmt.Errorf("application %s: %w", apps[i].ContractAddress, err)
+		}
+		status.Applications = append(status.Applications, *appStatus)
+	}
+	return &status, nil
+}
+
+func (s *EspressoReaderService) applicationSyncStatus(
+	ctx context.Context,
+	app *model.Application,
+	heads *SyncStatus,
+) (*ApplicationSyncStatus, error) {
+	address := app.ContractAddress
+	lastProcessedEspressoBlock, err := s.statusRepository.GetLastProcessedEspressoBlock(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	nextInputIndex, err := s.statusRepository.GetInputIndex(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	openEpoch, err := s.statusRepository.GetOpenEpoch(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	status := ApplicationSyncStatus{
+		ContractAddress:            address.Hex(),
+		Status:                     ApplicationSyncStatusStatusNOTRUNNING,
+		LastProcessedBlock:         app.LastProcessedBlock,
+		LastClaimCheckBlock:        app.LastClaimCheckBlock,
+		LastOutputCheckBlock:       app.LastOutputCheckBlock,
+		L1Lag:                      lag(heads.L1FinalizedBlock, app.LastProcessedBlock),
+		LastProcessedEspressoBlock: lastProcessedEspressoBlock,
+		EspressoLag:                lag(heads.EspressoLatestHeight, lastProcessedEspressoBlock),
+		NextInputIndex:             nextInputIndex,
+		LastError:                  s.errors.LastOf(address),
+	}
+	if app.Status == model.ApplicationStatusRunning {
+		status.Status = ApplicationSyncStatusStatusRUNNING
+	}
+	if openEpoch != nil {
+		status.OpenEpoch = &Epoch{
+			Index:      openEpoch.Index,
+			FirstBlock: openEpoch.FirstBlock,
+			LastBlock:  openEpoch.LastBlock,
+		}
+	}
+	return &status, nil
+}
+
+// lag returns how many blocks the head is ahead of the block, nil if the head is unknown
+func lag(head *uint64, block uint64) *uint64 {
+	if head == nil {
+		return nil
+	}
+	var blocks uint64
+	if *head > block {
+		blocks = *head - block
+	}
+	return &blocks
+}
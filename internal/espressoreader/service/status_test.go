@@ -0,0 +1,185 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusSuite struct {
+	suite.Suite
+	repository *fakeStatusRepository
+	heads      *fakeChainHeads
+	errors     *ErrorRecorder
+	handler    http.Handler
+}
+
+func TestStatusSuite(t *testing.T) {
+	suite.Run(t, new(StatusSuite))
+}
+
+func (s *StatusSuite) SetupTest() {
+	s.repository = &fakeStatusRepository{
+		apps: []model.Application{{
+			ContractAddress:      validationTestApp,
+			Status:               model.ApplicationStatusRunning,
+			LastProcessedBlock:   90,
+			LastClaimCheckBlock:  80,
+			LastOutputCheckBlock: 70,
+		}, {
+			ContractAddress:    validationTestDisabledApp,
+			Status:             model.ApplicationStatusNotRunning,
+			LastProcessedBlock: 120,
+		}},
+		espressoBlocks: map[common.Address]uint64{validationTestApp: 1000},
+		inputIndexes:   map[common.Address]uint64{validationTestApp: 7},
+		openEpochs: map[common.Address]*model.Epoch{
+			validationTestApp: {Index: 9, FirstBlock: 90, LastBlock: 99},
+		},
+	}
+	s.heads = &fakeChainHeads{l1FinalizedBlock: 100, espressoLatestHeight: 1500}
+	s.errors = NewErrorRecorder(slog.NewTextHandler(io.Discard, nil))
+	service := &EspressoReaderService{
+		statusRepository: s.repository,
+		heads:            s.heads,
+		errors:           s.errors,
+	}
+	s.handler = NewHandler(service, HttpServerConfig{
+		MaxRequestSize: 1024,
+		Auth:           AuthConfig{AnonymousScopes: []Scope{ScopeRead}},
+	})
+}
+
+func (s *StatusSuite) getStatus() SyncStatus {
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+	var status SyncStatus
+	s.Require().Nil(json.Unmarshal(recorder.Body.Bytes(), &status))
+	return status
+}
+
+func uint64Pointer(value uint64) *uint64 {
+	return &value
+}
+
+func (s *StatusSuite) TestItReportsTheLagOfEachApplication() {
+	status := s.getStatus()
+	s.Equal(uint64Pointer(100), status.L1FinalizedBlock)
+	s.Equal(uint64Pointer(1500), status.EspressoLatestHeight)
+	s.Nil(status.LastError)
+	s.Require().Len(status.Applications, 2)
+
+	app := status.Applications[0]
+	s.Equal(validationTestApp.Hex(), app.ContractAddress)
+	s.Equal(ApplicationSyncStatusStatusRUNNING, app.Status)
+	s.Equal(uint64(90), app.LastProcessedBlock)
+	s.Equal(uint64(80), app.LastClaimCheckBlock)
+	s.Equal(uint64(70), app.LastOutputCheckBlock)
+	s.Equal(uint64Pointer(10), app.L1Lag)
+	s.Equal(uint64(1000), app.LastProcessedEspressoBlock)
+	s.Equal(uint64Pointer(500), app.EspressoLag)
+	s.Equal(uint64(7), app.NextInputIndex)
+	s.Equal(&Epoch{Index: 9, FirstBlock: 90, LastBlock: 99}, app.OpenEpoch)
+
+	// applications ahead of a lagging node have no lag
+	disabled := status.Applications[1]
+	s.Equal(ApplicationSyncStatusStatusNOTRUNNING, disabled.Status)
+	s.Equal(uint64Pointer(0), disabled.L1Lag)
+	s.Equal(uint64Pointer(1500), disabled.EspressoLag)
+	s.Nil(disabled.OpenEpoch)
+}
+
+func (s *StatusSuite) TestItOmitsUnreachableHeads() {
+	s.heads.err = errors.New("connection refused")
+	status := s.getStatus()
+	s.Nil(status.L1FinalizedBlock)
+	s.Nil(status.EspressoLatestHeight)
+	s.Require().Len(status.Applications, 2)
+	s.Nil(status.Applications[0].L1Lag)
+	s.Nil(status.Applications[0].EspressoLag)
+}
+
+func (s *StatusSuite) TestItReportsTheLastErrors() {
+	logger := slog.New(s.errors)
+	logger.Error("failed reading inputs", "app", validationTestApp, "error", errors.New("timeout"))
+	logger.With("app", validationTestDisabledApp.Hex()).Error("failed fetching espresso tx")
+	logger.Error("failed fetching latest espresso block height", "error", "unreachable")
+	logger.Warn("not an error", "app", validationTestApp)
+
+	status := s.getStatus()
+	s.Require().NotNil(status.LastError)
+	s.Equal("failed fetching latest espresso block height: unreachable", status.LastError.Message)
+	s.Require().NotNil(status.Applications[0].LastError)
+	s.Equal("failed reading inputs: timeout", status.Applications[0].LastError.Message)
+	s.Require().NotNil(status.Applications[1].LastError)
+	s.Equal("failed fetching espresso tx", status.Applications[1].LastError.Message)
+}
+
+func (s *StatusSuite) TestItFailsWhenTheDatabaseFails() {
+	s.repository.err = errors.New("database is down")
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	s.Equal(http.StatusInternalServerError, recorder.Code)
+}
+
+type fakeStatusRepository struct {
+	apps           []model.Application
+	espressoBlocks map[common.Address]uint64
+	inputIndexes   map[common.Address]uint64
+	openEpochs     map[common.Address]*model.Epoch
+	schemaErr      error
+	err            error
+}
+
+func (r *fakeStatusRepository) GetAllApplications(ctx context.Context) ([]model.Application, error) {
+	return r.apps, r.err
+}
+
+func (r *fakeStatusRepository) GetLastProcessedEspressoBlock(
+	ctx context.Context,
+	app common.Address,
+) (uint64, error) {
+	return r.espressoBlocks[app], r.err
+}
+
+func (r *fakeStatusRepository) GetInputIndex(ctx context.Context, app common.Address) (uint64, error) {
+	return r.inputIndexes[app], r.err
+}
+
+func (r *fakeStatusRepository) GetOpenEpoch(ctx context.Context, app common.Address) (*model.Epoch, error) {
+	return r.openEpochs[app], r.err
+}
+
+func (r *fakeStatusRepository) CheckSchema(ctx context.Context) error {
+	return r.schemaErr
+}
+
+type fakeChainHeads struct {
+	l1FinalizedBlock     uint64
+	espressoLatestHeight uint64
+	err                  error
+}
+
+func (h *fakeChainHeads) L1FinalizedBlock(ctx context.Context) (uint64, error) {
+	return h.l1FinalizedBlock, h.err
+}
+
+func (h *fakeChainHeads) EspressoLatestHeight(ctx context.Context) (uint64, error) {
+	return h.espressoLatestHeight, h.err
+}
+
+func (h *fakeChainHeads) Close() {}
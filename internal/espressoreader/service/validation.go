@@ -0,0 +1,166 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Codes of the errors returned by the Espresso service
+const (
+	ErrCodeBadRequest          = "bad_request"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeMethodNotAllowed    = "method_not_allowed"
+	ErrCodeInvalidSignature    = "invalid_signature"
+	ErrCodeInvalidTypedData    = "invalid_typed_data"
+	ErrCodePayloadTooLarge     = "payload_too_large"
+	ErrCodeApplicationNotFound = "application_not_found"
+	ErrCodeApplicationDisabled = "application_not_running"
+	ErrCodeInvalidNonce        = "invalid_nonce"
+	ErrCodeRateLimited         = "rate_limited"
+	ErrCodeUnauthorized        = "unauthorized"
+	ErrCodeForbidden           = "forbidden"
+	ErrCodeInternal            = "internal_error"
+	ErrCodeEspressoUnavailable = "espresso_unavailable"
+)
+
+// ValidationError is returned when a submission is rejected
+// before it is forwarded to Espresso
+type ValidationError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func newValidationError(status int, code string, format string, args ...any) *ValidationError {
+	return &ValidationError{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ApplicationRepository is the part of the repository the SubmissionValidator
+// uses to look up applications
+type ApplicationRepository interface {
+	GetApplication(ctx context.Context, appAddressKey common.Address) (*model.Application, error)
+}
+
+// Submission is a transaction that passed validation and can be sent to Espresso
+type Submission struct {
+	// Base64 encoded SigAndData, as expected by the reader
+	Payload   []byte
+	Sender    common.Address
+	TypedData apitypes.TypedData
+	Message   *typeddata.CartesiMessage
+	TxId      string
+}
+
+// SubmissionValidator checks transactions sent to the /submit endpoint,
+// so that only inputs the reader would accept are sequenced by Espresso
+type SubmissionValidator struct {
+	repository     ApplicationRepository
+	nonceManager   *NonceManager
+	chainId        uint64
+	maxPayloadSize uint64
+}
+
+func NewSubmissionValidator(
+	repository ApplicationRepository,
+	nonceManager *NonceManager,
+	chainId uint64,
+	maxPayloadSize uint64,
+) *SubmissionValidator {
+	return &SubmissionValidator{
+		repository:     repository,
+		nonceManager:   nonceManager,
+		chainId:        chainId,
+		maxPayloadSize: maxPayloadSize,
+	}
+}
+
+// Validate checks the signature, the typed data schema, the target application,
+// the nonce and the payload size of a SigAndData JSON document.
+// Rejections are reported as *ValidationError.
+// The nonce of an accepted submission is reserved, and must be released
+// with Release if the submission doesn't reach Espresso.
+func (v *SubmissionValidator) Validate(ctx context.Context, body []byte) (*Submission, error) {
+	var sigAndData typeddata.SigAndData
+	if err := json.Unmarshal(body, &sigAndData); err != nil {
+		return nil, newValidationError(http.StatusBadRequest, ErrCodeBadRequest,
+			"body must be a SigAndData JSON document: %v", err)
+	}
+
+	payload := []byte(base64.StdEncoding.EncodeToString(body))
+	sender, typedData, txId, err := typeddata.ExtractSigAndData(string(payload))
+	if err != nil {
+		return nil, newValidationError(http.StatusBadRequest, ErrCodeInvalidSignature,
+			"could not recover the signer: %v", err)
+	}
+	if sigAndData.Account != "" && common.HexToAddress(sigAndData.Account) != sender {
+		return nil, newValidationError(http.StatusBadRequest, ErrCodeInvalidSignature,
+			"message was signed by %s, not by account %s", sender, sigAndData.Account)
+	}
+
+	err = typeddata.Validate(typedData, v.chainId)
+	if err != nil {
+		return nil, newValidationError(http.StatusBadRequest, ErrCodeInvalidTypedData, "%v", err)
+	}
+	message, err := typeddata.ParseCartesiMessage(typedData.Message)
+	if err != nil {
+		return nil, newValidationError(http.StatusBadRequest, ErrCodeInvalidTypedData,
+			"invalid message: %v", err)
+	}
+
+	if uint64(len(message.Data)) > v.maxPayloadSize {
+		return nil, newValidationError(http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge,
+			"payload has %d bytes, the limit is %d", len(message.Data), v.maxPayloadSize)
+	}
+
+	app, err := v.repository.GetApplication(ctx, message.App)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application %s: %w", message.App, err)
+	}
+	if app == nil {
+		return nil, newValidationError(http.StatusNotFound, ErrCodeApplicationNotFound,
+			"application %s is not registered", message.App)
+	}
+	if app.Status != model.ApplicationStatusRunning {
+		return nil, newValidationError(http.StatusUnprocessableEntity, ErrCodeApplicationDisabled,
+			"application %s is not running", message.App)
+	}
+
+	// the nonce is reserved last, so no other check can reject the submission after it
+	expectedNonce, reserved, err := v.nonceManager.Reserve(ctx, message.App, sender, message.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve nonce: %w", err)
+	}
+	if !reserved {
+		return nil, newValidationError(http.StatusConflict, ErrCodeInvalidNonce,
+			"expected nonce %d for sender %s, got %d", expectedNonce, sender, message.Nonce)
+	}
+
+	return &Submission{
+		Payload:   payload,
+		Sender:    sender,
+		TypedData: typedData,
+		Message:   message,
+		TxId:      txId,
+	}, nil
+}
+
+// Release drops the nonce reservation of a submission that didn't reach Espresso
+func (v *SubmissionValidator) Release(submission *Submission) {
+	v.nonceManager.Release(submission.Message.App, submission.Sender, submission.Message.Nonce)
+}
@@ -0,0 +1,378 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
+
+	tagged_base64 "github.com/EspressoSystems/espresso-sequencer-go/tagged-base64"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const validationTestChainId = 11155111
+
+var (
+	validationTestApp         = common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+	validationTestDisabledApp = common.HexToAddress("0xdeadbeef")
+	validationTestUnknownApp  = common.HexToAddress("0xfeedbeef")
+)
+
+type ValidationSuite struct {
+	suite.Suite
+	ctx          context.Context
+	key          *ecdsa.PrivateKey
+	sender       common.Address
+	repository   *MockApplicationRepository
+	nonces       *FakeNonceRepository
+	nonceManager *NonceManager
+	validator    *SubmissionValidator
+}
+
+func TestValidationSuite(t *testing.T) {
+	suite.Run(t, new(ValidationSuite))
+}
+
+func (s *ValidationSuite) SetupTest() {
+	var err error
+	s.ctx = context.Background()
+	s.key, err = crypto.GenerateKey()
+	s.Require().Nil(err)
+	s.sender = crypto.PubkeyToAddress(s.key.PublicKey)
+
+	s.repository = newMockApplicationRepository()
+	s.nonces = newFakeNonceRepository()
+	s.nonceManager = NewNonceManager(s.nonces, time.Minute)
+	s.validator = NewSubmissionValidator(s.repository, s.nonceManager, validationTestChainId, 16)
+}
+
+func (s *ValidationSuite) TestItAcceptsAValidSubmission() {
+	s.nonces.set(validationTestApp, s.sender, 3)
+	typedData := newTestTypedData(validationTestApp, 3, "0xdeadbeef")
+
+	submission, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+	s.Require().Nil(err)
+	s.Equal(s.sender, submission.Sender)
+	s.Equal(validationTestApp, submission.Message.App)
+	s.Equal(uint64(3), submission.Message.Nonce)
+	s.Equal(common.FromHex("0xdeadbeef"), submission.Message.Data)
+	s.True(strings.HasPrefix(submission.TxId, "0x"))
+
+	// the payload is what the reader expects to find on Espresso
+	sender, _, txId, err := typeddata.ExtractSigAndData(string(submission.Payload))
+	s.Require().Nil(err)
+	s.Equal(s.sender, sender)
+	s.Equal(submission.TxId, txId)
+}
+
+func (s *ValidationSuite) TestItAcceptsTheNextPendingNonce() {
+	s.nonceManager.Add(validationTestApp, s.sender, 0)
+	typedData := newTestTypedData(validationTestApp, 1, "0x")
+
+	_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+	s.Nil(err)
+}
+
+func (s *ValidationSuite) TestItRejectsMalformedBodies() {
+	for _, body := range []string{"", "not json", `["typedData"]`} {
+		_, err := s.validator.Validate(s.ctx, []byte(body))
+		s.assertValidationError(err, http.StatusBadRequest, ErrCodeBadRequest)
+	}
+}
+
+func (s *ValidationSuite) TestItRejectsInvalidSignatures() {
+	for _, body := range []string{
+		`{"typedData": {}, "account": "0x", "signature": "0x1234"}`,
+		`{"typedData": {}, "account": "0x", "signature": "zz"}`,
+	} {
+		_, err := s.validator.Validate(s.ctx, []byte(body))
+		s.assertValidationError(err, http.StatusBadRequest, ErrCodeInvalidSignature)
+	}
+}
+
+func (s *ValidationSuite) TestItRejectsTamperedMessages() {
+	body := signTestTypedData(s.T(), s.key, newTestTypedData(validationTestApp, 0, "0xdeadbeef"))
+	var sigAndData typeddata.SigAndData
+	s.Require().Nil(json.Unmarshal(body, &sigAndData))
+	sigAndData.TypedData.Message["data"] = "0xfeedbeef"
+	tampered, err := json.Marshal(sigAndData)
+	s.Require().Nil(err)
+
+	_, err = s.validator.Validate(s.ctx, tampered)
+	s.assertValidationError(err, http.StatusBadRequest, ErrCodeInvalidSignature)
+}
+
+func (s *ValidationSuite) TestItRejectsInvalidSchemas() {
+	wrongPrimaryType := newTestTypedData(validationTestApp, 0, "0x")
+	wrongPrimaryType.Types["Other"] = wrongPrimaryType.Types[typeddata.PrimaryType]
+	wrongPrimaryType.PrimaryType = "Other"
+
+	wrongFields := newTestTypedData(validationTestApp, 0, "0x")
+	wrongFields.Types[typeddata.PrimaryType] = []apitypes.Type{
+		{Name: "app", Type: "address"},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "data", Type: "bytes"},
+	}
+	delete(wrongFields.Message, "max_gas_price")
+
+	wrongDomain := newTestTypedData(validationTestApp, 0, "0x")
+	wrongDomain.Domain.Name = "Other"
+
+	wrongChain := newTestTypedData(validationTestApp, 0, "0x")
+	wrongChain.Domain.ChainId = math.NewHexOrDecimal256(1)
+
+	for _, typedData := range []apitypes.TypedData{
+		wrongPrimaryType, wrongFields, wrongDomain, wrongChain,
+	} {
+		_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+		s.assertValidationError(err, http.StatusBadRequest, ErrCodeInvalidTypedData)
+	}
+}
+
+func (s *ValidationSuite) TestItRejectsLargePayloads() {
+	typedData := newTestTypedData(validationTestApp, 0, hexutil.Encode(make([]byte, 17)))
+	_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+	s.assertValidationError(err, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge)
+}
+
+func (s *ValidationSuite) TestItRejectsUnknownApplications() {
+	typedData := newTestTypedData(validationTestUnknownApp, 0, "0x")
+	_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+	s.assertValidationError(err, http.StatusNotFound, ErrCodeApplicationNotFound)
+}
+
+func (s *ValidationSuite) TestItRejectsApplicationsNotRunning() {
+	typedData := newTestTypedData(validationTestDisabledApp, 0, "0x")
+	_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+	s.assertValidationError(err, http.StatusUnprocessableEntity, ErrCodeApplicationDisabled)
+}
+
+func (s *ValidationSuite) TestItRejectsUnexpectedNonces() {
+	s.nonces.set(validationTestApp, s.sender, 2)
+	for _, nonce := range []uint64{1, 3} {
+		typedData := newTestTypedData(validationTestApp, nonce, "0x")
+		_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+		s.assertValidationError(err, http.StatusConflict, ErrCodeInvalidNonce)
+	}
+}
+
+func (s *ValidationSuite) TestItFailsWhenTheRepositoryFails() {
+	repository := &MockApplicationRepository{}
+	repository.On("GetApplication", mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection refused"))
+	s.validator = NewSubmissionValidator(repository, s.nonceManager, validationTestChainId, 16)
+
+	typedData := newTestTypedData(validationTestApp, 0, "0x")
+	_, err := s.validator.Validate(s.ctx, signTestTypedData(s.T(), s.key, typedData))
+	s.Require().NotNil(err)
+	var validationErr *ValidationError
+	s.False(errors.As(err, &validationErr))
+}
+
+func (s *ValidationSuite) TestSubmitRespondsWithJSONErrors() {
+	service := &EspressoReaderService{
+		nonceManager:        s.nonceManager,
+		submissionValidator: s.validator,
+		rateLimiter:         NewSubmitRateLimiter(RateLimitConfig{}),
+	}
+	typedData := newTestTypedData(validationTestApp, 5, "0x")
+	request := httptest.NewRequest(http.MethodPost, "/submit",
+		bytes.NewReader(signTestTypedData(s.T(), s.key, typedData)))
+	recorder := httptest.NewRecorder()
+
+	service.SubmitTransaction(recorder, request)
+
+	s.Equal(http.StatusConflict, recorder.Code)
+	s.Equal("application/json", recorder.Header().Get("Content-Type"))
+	var response ErrorResponse
+	s.Require().Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+	s.Equal(ErrCodeInvalidNonce, response.Code)
+	s.Contains(response.Message, "expected nonce 0")
+}
+
+func (s *ValidationSuite) TestItReservesTheNonceOfAcceptedSubmissions() {
+	body := signTestTypedData(s.T(), s.key, newTestTypedData(validationTestApp, 0, "0x"))
+	_, err := s.validator.Validate(s.ctx, body)
+	s.Require().Nil(err)
+
+	_, err = s.validator.Validate(s.ctx, body)
+	s.assertValidationError(err, http.StatusConflict, ErrCodeInvalidNonce)
+}
+
+// newEspresso returns a fake Espresso that accepts, or fails, every submission
+func (s *ValidationSuite) newEspresso(status int) (*httptest.Server, *atomic.Int32) {
+	var submitted atomic.Int32
+	commitment, err := tagged_base64.New("TX", []byte{1})
+	s.Require().Nil(err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// hold the submission, so concurrent ones overlap
+		time.Sleep(10 * time.Millisecond)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		submitted.Add(1)
+		json.NewEncoder(w).Encode(commitment)
+	}))
+	s.T().Cleanup(server.Close)
+	return server, &submitted
+}
+
+func (s *ValidationSuite) newSubmitService(espressoUrl string) *EspressoReaderService {
+	return &EspressoReaderService{
+		EspressoBaseUrl:     espressoUrl,
+		nonceManager:        s.nonceManager,
+		submissionValidator: s.validator,
+		rateLimiter:         NewSubmitRateLimiter(RateLimitConfig{}),
+	}
+}
+
+func (s *ValidationSuite) submit(service *EspressoReaderService, body []byte) int {
+	request := httptest.NewRequest(http.MethodPost, "/submit", bytes.NewReader(body))
+	recorder := httptest.NewRecorder()
+	service.SubmitTransaction(recorder, request)
+	return recorder.Code
+}
+
+func (s *ValidationSuite) TestSubmitForwardsOneOfConcurrentSubmissionsOfANonce() {
+	espresso, submitted := s.newEspresso(http.StatusOK)
+	service := s.newSubmitService(espresso.URL)
+	body := signTestTypedData(s.T(), s.key, newTestTypedData(validationTestApp, 0, "0x"))
+
+	const requests = 8
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- s.submit(service, body)
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	count := map[int]int{}
+	for code := range codes {
+		count[code]++
+	}
+	s.Equal(map[int]int{http.StatusOK: 1, http.StatusConflict: requests - 1}, count)
+	s.Equal(int32(1), submitted.Load())
+}
+
+func (s *ValidationSuite) TestSubmitReleasesTheNonceWhenEspressoFails() {
+	espresso, _ := s.newEspresso(http.StatusServiceUnavailable)
+	body := signTestTypedData(s.T(), s.key, newTestTypedData(validationTestApp, 0, "0x"))
+	s.Equal(http.StatusBadGateway, s.submit(s.newSubmitService(espresso.URL), body))
+
+	nonce, err := s.nonceManager.Next(s.ctx, validationTestApp, s.sender)
+	s.Require().Nil(err)
+	s.Equal(uint64(0), nonce)
+
+	espresso, _ = s.newEspresso(http.StatusOK)
+	s.Equal(http.StatusOK, s.submit(s.newSubmitService(espresso.URL), body))
+}
+
+func (s *ValidationSuite) assertValidationError(err error, status int, code string) {
+	var validationErr *ValidationError
+	s.Require().True(errors.As(err, &validationErr), "unexpected error: %v", err)
+	s.Equal(status, validationErr.Status, validationErr.Message)
+	s.Equal(code, validationErr.Code, validationErr.Message)
+}
+
+func newTestTypedData(app common.Address, nonce uint64, data string) apitypes.TypedData {
+	types := apitypes.Types{}
+	for name, fields := range typeddata.Types {
+		types[name] = append([]apitypes.Type{}, fields...)
+	}
+	return apitypes.TypedData{
+		Types:       types,
+		PrimaryType: typeddata.PrimaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              typeddata.DomainName,
+			Version:           typeddata.DomainVersion,
+			ChainId:           math.NewHexOrDecimal256(validationTestChainId),
+			VerifyingContract: common.Address{}.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"app":           app.Hex(),
+			"nonce":         float64(nonce),
+			"max_gas_price": "10",
+			"data":          data,
+		},
+	}
+}
+
+// signTestTypedData returns the SigAndData JSON document a client would send to /submit
+func signTestTypedData(t *testing.T, key *ecdsa.PrivateKey, typedData apitypes.TypedData) []byte {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	body, err := json.Marshal(typeddata.SigAndData{
+		TypedData: typedData,
+		Account:   crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Signature: hexutil.Encode(signature),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+type MockApplicationRepository struct {
+	mock.Mock
+}
+
+func newMockApplicationRepository() *MockApplicationRepository {
+	repository := &MockApplicationRepository{}
+	repository.On("GetApplication", mock.Anything, validationTestApp).Return(
+		&model.Application{
+			ContractAddress: validationTestApp,
+			Status:          model.ApplicationStatusRunning,
+		}, nil)
+	repository.On("GetApplication", mock.Anything, validationTestDisabledApp).Return(
+		&model.Application{
+			ContractAddress: validationTestDisabledApp,
+			Status:          model.ApplicationStatusNotRunning,
+		}, nil)
+	repository.On("GetApplication", mock.Anything, mock.Anything).Return(nil, nil)
+	return repository
+}
+
+func (m *MockApplicationRepository) GetApplication(
+	ctx context.Context,
+	appAddressKey common.Address,
+) (*model.Application, error) {
+	args := m.Called(ctx, appAddressKey)
+	obj := args.Get(0)
+	if obj == nil {
+		return nil, args.Error(1)
+	}
+	return obj.(*model.Application), args.Error(1)
+}
@@ -4,6 +4,7 @@
 package evmreader
 
 import (
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
 	appcontract "github.com/ZzzzHui/espresso-reader/pkg/contracts/iapplication"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -35,7 +36,9 @@ func (a *ApplicationContractAdapter) GetConsensus(opts *bind.CallOpts) (common.A
 
 func (a *ApplicationContractAdapter) RetrieveOutputExecutionEvents(
 	opts *bind.FilterOpts,
-) ([]*appcontract.IApplicationOutputExecuted, error) {
+) (_ []*appcontract.IApplicationOutputExecuted, err error) {
+	opts, span := startFilterSpan(opts, "IApplication::RetrieveOutputExecutionEvents")
+	defer func() { tracing.End(span, err) }()
 
 	itr, err := a.application.FilterOutputExecuted(opts)
 	if err != nil {
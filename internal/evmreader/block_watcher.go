@@ -0,0 +1,80 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package evmreader
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// BlockWatcher tells the EvmReader about the new blocks of the chain.
+// An EthWsClient watches them through a WebSocket subscription,
+// and a PollingBlockWatcher through an HTTP endpoint.
+type BlockWatcher interface {
+	// SubscribeNewHead sends the header of each new block to ch
+	// until the subscription is unsubscribed or fails
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// PollingBlockWatcher watches for new blocks by polling the latest header,
+// for the endpoints that do not support subscriptions
+type PollingBlockWatcher struct {
+	client   EthClient
+	interval time.Duration
+}
+
+func NewPollingBlockWatcher(client EthClient, interval time.Duration) *PollingBlockWatcher {
+	return &PollingBlockWatcher{client: client, interval: interval}
+}
+
+// SubscribeNewHead reads the latest header every interval and sends it to ch
+// whenever its block is newer than the last one sent.
+// The subscription fails when a read fails.
+func (w *PollingBlockWatcher) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (ethereum.Subscription, error) {
+	header, err := w.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		var last *big.Int
+		for {
+			if last == nil || header.Number.Cmp(last) > 0 {
+				select {
+				case ch <- header:
+					last = header.Number
+				case <-quit:
+					return nil
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-quit:
+				return nil
+			}
+
+			header, err = w.client.HeaderByNumber(ctx, nil)
+			if err != nil {
+				if ctx.Err() != nil {
+					// the reader is stopping, which is not a failure of the subscription
+					<-quit
+					return nil
+				}
+				return err
+			}
+		}
+	}), nil
+}
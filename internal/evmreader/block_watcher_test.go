@@ -0,0 +1,168 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package evmreader
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/ZzzzHui/espresso-reader/internal/model"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeEthClient returns its headers in order, then fails with err if it is set,
+// or keeps returning the last header
+type fakeEthClient struct {
+	mu      sync.Mutex
+	headers []*types.Header
+	next    int
+	err     error
+}
+
+func newFakeEthClient(blockNumbers ...int64) *fakeEthClient {
+	client := &fakeEthClient{}
+	for _, number := range blockNumbers {
+		client.headers = append(client.headers, &types.Header{Number: big.NewInt(number)})
+	}
+	return client
+}
+
+func (c *fakeEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.next < len(c.headers) {
+		c.next++
+		return c.headers[c.next-1], nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.headers[len(c.headers)-1], nil
+}
+
+type BlockWatcherSuite struct {
+	suite.Suite
+	ctx     context.Context
+	cancel  context.CancelFunc
+	headers chan *types.Header
+}
+
+func TestBlockWatcherSuite(t *testing.T) {
+	suite.Run(t, new(BlockWatcherSuite))
+}
+
+func (s *BlockWatcherSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	s.headers = make(chan *types.Header)
+}
+
+func (s *BlockWatcherSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *BlockWatcherSuite) receive() *types.Header {
+	select {
+	case header := <-s.headers:
+		return header
+	case <-s.ctx.Done():
+		s.FailNow("no new block")
+		return nil
+	}
+}
+
+func (s *BlockWatcherSuite) TestItSendsEachNewBlockOnce() {
+	watcher := NewPollingBlockWatcher(newFakeEthClient(1, 1, 2, 2, 2, 4, 3, 5), time.Millisecond)
+	sub, err := watcher.SubscribeNewHead(s.ctx, s.headers)
+	s.Require().Nil(err)
+	defer sub.Unsubscribe()
+
+	for _, number := range []uint64{1, 2, 4, 5} {
+		s.Equal(number, s.receive().Number.Uint64())
+	}
+}
+
+func (s *BlockWatcherSuite) TestItFailsWhenAReadFails() {
+	client := newFakeEthClient()
+	client.err = errors.New("connection refused")
+	_, err := NewPollingBlockWatcher(client, time.Millisecond).SubscribeNewHead(s.ctx, s.headers)
+	s.EqualError(err, "connection refused")
+
+	client = newFakeEthClient(1)
+	client.err = errors.New("connection reset")
+	sub, err := NewPollingBlockWatcher(client, time.Millisecond).SubscribeNewHead(s.ctx, s.headers)
+	s.Require().Nil(err)
+	defer sub.Unsubscribe()
+	s.Equal(uint64(1), s.receive().Number.Uint64())
+	select {
+	case err := <-sub.Err():
+		s.EqualError(err, "connection reset")
+	case <-s.ctx.Done():
+		s.FailNow("the subscription didn't fail")
+	}
+}
+
+func (s *BlockWatcherSuite) TestItStopsWhenUnsubscribed() {
+	sub, err := NewPollingBlockWatcher(newFakeEthClient(1), time.Millisecond).SubscribeNewHead(s.ctx, s.headers)
+	s.Require().Nil(err)
+	s.Equal(uint64(1), s.receive().Number.Uint64())
+	sub.Unsubscribe()
+	select {
+	case err, ok := <-sub.Err():
+		s.False(ok, "unexpected error %v", err)
+	case <-s.ctx.Done():
+		s.FailNow("the subscription didn't stop")
+	}
+}
+
+func (s *EvmReaderSuite) TestItReadsNewBlocksByPolling() {
+	polled := make(chan struct{}, 1)
+	s.repository.Unset("GetAllRunningApplications")
+	s.repository.On("GetAllRunningApplications", mock.Anything).Return([]Application{}, nil).
+		Run(func(args mock.Arguments) {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+		})
+
+	evmReader := NewEvmReader(
+		s.client,
+		NewPollingBlockWatcher(newFakeEthClient(1, 2), time.Millisecond),
+		s.inputBox,
+		s.repository,
+		0,
+		DefaultBlockStatusLatest,
+		s.contractFactory,
+		false,
+	)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	ready := make(chan struct{}, 1)
+	errChannel := make(chan error, 1)
+	go func() {
+		errChannel <- evmReader.Run(ctx, ready)
+	}()
+
+	select {
+	case <-ready:
+	case err := <-errChannel:
+		s.FailNow("unexpected failure", err)
+	}
+	// each new block makes the reader look for the running applications
+	select {
+	case <-polled:
+	case err := <-errChannel:
+		s.FailNow("unexpected failure", err)
+	}
+
+	cancel()
+	s.Equal(context.Canceled, <-errChannel)
+}
@@ -113,7 +113,7 @@ func (r *EvmReader) readAndUpdateClaims(
 				if err != nil {
 					slog.Error("evmreader: Error retrieving previous submitted claims",
 						"app", app,
-						"block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
+						"l1_block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
 						"error", err)
 					continue APP_LOOP
 				}
@@ -133,7 +133,7 @@ func (r *EvmReader) readAndUpdateClaims(
 				if err != nil {
 					slog.Error("evmreader: Error retrieving Epoch",
 						"app", app,
-						"block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
+						"l1_block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
 						"error", err)
 					continue APP_LOOP
 				}
@@ -167,7 +167,7 @@ func (r *EvmReader) readAndUpdateClaims(
 				if epoch.Status == EpochStatusClaimAccepted {
 					slog.Debug("evmreader: Claim already accepted. Skipping",
 						"app", app,
-						"block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
+						"l1_block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
 						"claimStatus", epoch.Status,
 						"hash", epoch.ClaimHash)
 					continue
@@ -177,7 +177,7 @@ func (r *EvmReader) readAndUpdateClaims(
 					// the claim is marked as submitted by the claimer.
 					slog.Debug("evmreader: Claim status is not submitted. Skipping for now",
 						"app", app,
-						"block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
+						"l1_block", claimAcceptance.LastProcessedBlockNumber.Uint64(),
 						"claimStatus", epoch.Status,
 						"hash", epoch.ClaimHash)
 					continue APP_LOOP
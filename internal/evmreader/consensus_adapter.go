@@ -6,6 +6,7 @@ package evmreader
 import (
 	"math/big"
 
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
 	"github.com/ZzzzHui/espresso-reader/pkg/contracts/iconsensus"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -38,7 +39,9 @@ func (c *ConsensusContractAdapter) GetEpochLength(opts *bind.CallOpts) (*big.Int
 func (c *ConsensusContractAdapter) RetrieveClaimAcceptanceEvents(
 	opts *bind.FilterOpts,
 	appAddresses []common.Address,
-) ([]*iconsensus.IConsensusClaimAcceptance, error) {
+) (_ []*iconsensus.IConsensusClaimAcceptance, err error) {
+	opts, span := startFilterSpan(opts, "IConsensus::RetrieveClaimAcceptanceEvents")
+	defer func() { tracing.End(span, err) }()
 
 	itr, err := c.consensus.FilterClaimAcceptance(opts, appAddresses)
 	if err != nil {
@@ -5,11 +5,11 @@ package evmreader
 
 import (
 	"context"
+	_ "embed"
 	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
-	"os"
 	"strings"
 
 	. "github.com/ZzzzHui/espresso-reader/internal/model"
@@ -24,6 +24,9 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+//go:embed abi.json
+var ioAbiJson string
+
 // Interface for Input reading
 type InputSource interface {
 	// Wrapper for FilterInputAdded(), which is automatically generated
@@ -119,7 +122,7 @@ type application struct {
 // Output Executed events from the blockchain
 type EvmReader struct {
 	client                  EthClient
-	wsClient                EthWsClient
+	blockWatcher            BlockWatcher
 	inputSource             InputSource
 	repository              EvmReaderRepository
 	contractFactory         ContractFactory
@@ -138,7 +141,7 @@ func (r *EvmReader) String() string {
 // Creates a new EvmReader
 func NewEvmReader(
 	client EthClient,
-	wsClient EthWsClient,
+	blockWatcher BlockWatcher,
 	inputSource InputSource,
 	repository EvmReaderRepository,
 	inputBoxDeploymentBlock uint64,
@@ -146,17 +149,13 @@ func NewEvmReader(
 	contractFactory ContractFactory,
 	shouldModifyIndex bool,
 ) EvmReader {
-	abiData, err := os.ReadFile("internal/evmreader/abi.json")
-	if err != nil {
-		panic(err)
-	}
-	ioABI, err := abi.JSON(strings.NewReader(string(abiData)))
+	ioABI, err := abi.JSON(strings.NewReader(ioAbiJson))
 	if err != nil {
 		panic(err)
 	}
 	evmReader := EvmReader{
 		client:                  client,
-		wsClient:                wsClient,
+		blockWatcher:            blockWatcher,
 		inputSource:             inputSource,
 		repository:              repository,
 		inputBoxDeploymentBlock: inputBoxDeploymentBlock,
@@ -175,7 +174,7 @@ func (r *EvmReader) Run(ctx context.Context, ready chan<- struct{}) error {
 	for {
 		err := r.watchForNewBlocks(ctx, ready)
 		// If the error is a SubscriptionError, re run watchForNewBlocks
-		// that it will restart the websocket subscription
+		// that it will restart the subscription to new blocks
 		if _, ok := err.(*SubscriptionError); !ok {
 			return err
 		}
@@ -188,7 +187,7 @@ func (r *EvmReader) Run(ctx context.Context, ready chan<- struct{}) error {
 // default block configuration, which have not been processed yet.
 func (r *EvmReader) watchForNewBlocks(ctx context.Context, ready chan<- struct{}) error {
 	headers := make(chan *types.Header)
-	sub, err := r.wsClient.SubscribeNewHead(ctx, headers)
+	sub, err := r.blockWatcher.SubscribeNewHead(ctx, headers)
 	if err != nil {
 		return fmt.Errorf("could not start subscription: %v", err)
 	}
@@ -205,7 +204,7 @@ func (r *EvmReader) watchForNewBlocks(ctx context.Context, ready chan<- struct{}
 		case header := <-headers:
 
 			// Every time a new block arrives
-			slog.Debug("evmreader: New block header received", "blockNumber", header.Number, "blockHash", header.Hash())
+			slog.Debug("evmreader: New block header received", "l1_block", header.Number, "blockHash", header.Hash())
 
 			slog.Debug("evmreader: Retrieving enabled applications")
 			// Get All Applications
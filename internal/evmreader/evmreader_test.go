@@ -459,6 +459,14 @@ func newMockRepository() *MockRepository {
 		mock.Anything,
 		mock.Anything).Return(nil)
 
+	repo.On("GetInputIndex",
+		mock.Anything,
+		mock.Anything).Return(uint64(0), nil)
+
+	repo.On("UpdateInputIndex",
+		mock.Anything,
+		mock.Anything).Return(nil)
+
 	outputHash := common.HexToHash("0xAABBCCDDEE")
 	repo.On("GetOutput",
 		mock.Anything,
@@ -661,6 +669,20 @@ func (m *MockRepository) UpdateOutputExecutionTransaction(
 	return args.Error(0)
 }
 
+func (m *MockRepository) GetInputIndex(
+	ctx context.Context, applicationAddress Address,
+) (uint64, error) {
+	args := m.Called(ctx, applicationAddress)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockRepository) UpdateInputIndex(
+	ctx context.Context, applicationAddress Address,
+) error {
+	args := m.Called(ctx, applicationAddress)
+	return args.Error(0)
+}
+
 type MockApplicationContract struct {
 	mock.Mock
 }
@@ -8,9 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math/big"
 
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
 	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/decoder"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -175,7 +176,7 @@ func (r *EvmReader) ReadAndStoreInputs(
 			slog.Info("evmreader: Found new Input",
 				"app", address,
 				"index", input.Index,
-				"block", input.BlockNumber,
+				"l1_block", input.BlockNumber,
 				"epoch_index", inputEpochIndex)
 
 			currentInputs, ok := epochInputMap[currentEpoch]
@@ -231,14 +232,16 @@ func (r *EvmReader) ReadAndStoreInputs(
 			)
 			continue
 		}
+		metrics.L1ProcessedBlock.WithLabelValues(address.Hex()).Set(float64(endBlock))
+		metrics.InputsIngested.WithLabelValues(address.Hex(), metrics.SourceInputBox).Add(float64(len(inputs)))
 
 		// Store everything
 		if len(epochInputMap) > 0 {
 
 			slog.Debug("evmreader: Inputs and epochs stored successfully",
 				"app", address,
-				"start-block", startBlock,
-				"end-block", endBlock,
+				"from_l1_block", startBlock,
+				"l1_block", endBlock,
 				"total epochs", len(epochInputMap),
 				"total inputs", len(inputs),
 			)
@@ -306,7 +309,7 @@ func (r *EvmReader) readInputsFromBlockchain(
 		slog.Debug("evmreader: Received input",
 			"app", event.AppContract,
 			"index", event.Index,
-			"block", event.Raw.BlockNumber)
+			"l1_block", event.Raw.BlockNumber)
 		input := &Input{
 			Index:            event.Index.Uint64(),
 			CompletionStatus: InputStatusNone,
@@ -343,41 +346,17 @@ func getEpochLength(consensus ConsensusContract) (uint64, error) {
 }
 
 func (r *EvmReader) modifyIndexInRaw(ctx context.Context, rawData []byte, appAddress common.Address, currentIndex uint64) ([]byte, error) {
-	// load contract ABI
-	abiObject := r.IOAbi
-	values, err := abiObject.Methods["EvmAdvance"].Inputs.Unpack(rawData[4:])
+	input, err := decoder.DecodeEvmAdvance(rawData)
 	if err != nil {
 		slog.Error("Error unpacking abi", "err", err)
 		return []byte{}, err
 	}
 
-	type EvmAdvance struct {
-		ChainId        *big.Int
-		AppContract    common.Address
-		MsgSender      common.Address
-		BlockNumber    *big.Int
-		BlockTimestamp *big.Int
-		PrevRandao     *big.Int
-		Index          *big.Int
-		Payload        []byte
-	}
-
-	data := EvmAdvance{
-		ChainId:        values[0].(*big.Int),
-		AppContract:    values[1].(common.Address),
-		MsgSender:      values[2].(common.Address),
-		BlockNumber:    values[3].(*big.Int),
-		BlockTimestamp: values[4].(*big.Int),
-		PrevRandao:     values[5].(*big.Int),
-		Index:          values[6].(*big.Int),
-		Payload:        values[7].([]byte),
-	}
-
 	// modify index
-	data.Index = big.NewInt(int64(currentIndex))
+	input.Index = currentIndex
 
 	// abi encode again
-	dataAbi, err := abiObject.Pack("EvmAdvance", data.ChainId, data.AppContract, data.MsgSender, data.BlockNumber, data.BlockTimestamp, data.PrevRandao, data.Index, data.Payload)
+	dataAbi, err := input.Encode()
 	if err != nil {
 		slog.Error("failed to abi encode", "error", err)
 		return []byte{}, err
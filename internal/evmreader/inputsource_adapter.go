@@ -6,6 +6,7 @@ package evmreader
 import (
 	"math/big"
 
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
 	"github.com/ZzzzHui/espresso-reader/pkg/contracts/iinputbox"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -35,7 +36,9 @@ func (i *InputSourceAdapter) RetrieveInputs(
 	opts *bind.FilterOpts,
 	appContract []common.Address,
 	index []*big.Int,
-) ([]iinputbox.IInputBoxInputAdded, error) {
+) (_ []iinputbox.IInputBoxInputAdded, err error) {
+	opts, span := startFilterSpan(opts, "InputBox::RetrieveInputs")
+	defer func() { tracing.End(span, err) }()
 
 	itr, err := i.inputbox.FilterInputAdded(opts, appContract, index)
 	if err != nil {
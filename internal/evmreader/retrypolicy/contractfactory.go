@@ -3,9 +3,8 @@
 package retrypolicy
 
 import (
-	"time"
-
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
+	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -14,22 +13,19 @@ import (
 // Builds contracts delegates that will
 // use retry policy on contract methods calls
 type EvmReaderContractFactory struct {
-	maxRetries      uint64
-	maxDelay        time.Duration
+	policy          *retry.Policy
 	ethClient       *ethclient.Client
 	iConsensusCache map[common.Address]evmreader.ConsensusContract
 }
 
 func NewEvmReaderContractFactory(
 	ethClient *ethclient.Client,
-	maxRetries uint64,
-	maxDelay time.Duration,
+	policy *retry.Policy,
 
 ) *EvmReaderContractFactory {
 	return &EvmReaderContractFactory{
 		ethClient:       ethClient,
-		maxRetries:      maxRetries,
-		maxDelay:        maxDelay,
+		policy:          policy,
 		iConsensusCache: make(map[common.Address]evmreader.ConsensusContract),
 	}
 }
@@ -45,7 +41,7 @@ func (f *EvmReaderContractFactory) NewApplication(
 		return nil, err
 	}
 
-	return NewApplicationWithRetryPolicy(applicationContract, f.maxRetries, f.maxDelay), nil
+	return NewApplicationWithRetryPolicy(applicationContract, f.policy), nil
 
 }
 
@@ -62,7 +58,7 @@ func (f *EvmReaderContractFactory) NewIConsensus(
 			return nil, err
 		}
 
-		delegator = NewConsensusWithRetryPolicy(consensus, f.maxRetries, f.maxDelay)
+		delegator = NewConsensusWithRetryPolicy(consensus, f.policy)
 
 		f.iConsensusCache[address] = delegator
 	}
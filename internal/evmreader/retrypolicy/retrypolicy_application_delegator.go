@@ -4,8 +4,6 @@
 package retrypolicy
 
 import (
-	"time"
-
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
 	"github.com/ZzzzHui/espresso-reader/pkg/contracts/iapplication"
@@ -15,29 +13,25 @@ import (
 )
 
 type ApplicationRetryPolicyDelegator struct {
-	delegate          evmreader.ApplicationContract
-	maxRetries        uint64
-	delayBetweenCalls time.Duration
+	delegate evmreader.ApplicationContract
+	policy   *retry.Policy
 }
 
 func NewApplicationWithRetryPolicy(
 	delegate evmreader.ApplicationContract,
-	maxRetries uint64,
-	delayBetweenCalls time.Duration,
+	policy *retry.Policy,
 ) *ApplicationRetryPolicyDelegator {
 	return &ApplicationRetryPolicyDelegator{
-		delegate:          delegate,
-		maxRetries:        maxRetries,
-		delayBetweenCalls: delayBetweenCalls,
+		delegate: delegate,
+		policy:   policy,
 	}
 }
 
 func (d *ApplicationRetryPolicyDelegator) GetConsensus(opts *bind.CallOpts,
 ) (common.Address, error) {
-	return retry.CallFunctionWithRetryPolicy(d.delegate.GetConsensus,
+	return retry.CallFunctionWithPolicy(d.delegate.GetConsensus,
 		opts,
-		d.maxRetries,
-		d.delayBetweenCalls,
+		d.policy,
 		"Application::GetConsensus",
 	)
 }
@@ -45,10 +39,9 @@ func (d *ApplicationRetryPolicyDelegator) GetConsensus(opts *bind.CallOpts,
 func (d *ApplicationRetryPolicyDelegator) RetrieveOutputExecutionEvents(
 	opts *bind.FilterOpts,
 ) ([]*iapplication.IApplicationOutputExecuted, error) {
-	return retry.CallFunctionWithRetryPolicy(d.delegate.RetrieveOutputExecutionEvents,
+	return retry.CallFunctionWithPolicy(d.delegate.RetrieveOutputExecutionEvents,
 		opts,
-		d.maxRetries,
-		d.delayBetweenCalls,
+		d.policy,
 		"Application::RetrieveOutputExecutionEvents",
 	)
 }
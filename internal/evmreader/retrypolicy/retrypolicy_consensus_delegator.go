@@ -5,7 +5,6 @@ package retrypolicy
 
 import (
 	"math/big"
-	"time"
 
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
@@ -19,20 +18,17 @@ import (
 // calls GetEpochLength with the retry
 // policy defined by util.RetryFunction
 type ConsensusRetryPolicyDelegator struct {
-	delegate          evmreader.ConsensusContract
-	maxRetries        uint64
-	delayBetweenCalls time.Duration
+	delegate evmreader.ConsensusContract
+	policy   *retry.Policy
 }
 
 func NewConsensusWithRetryPolicy(
 	delegate evmreader.ConsensusContract,
-	maxRetries uint64,
-	delayBetweenCalls time.Duration,
+	policy *retry.Policy,
 ) *ConsensusRetryPolicyDelegator {
 	return &ConsensusRetryPolicyDelegator{
-		delegate:          delegate,
-		maxRetries:        maxRetries,
-		delayBetweenCalls: delayBetweenCalls,
+		delegate: delegate,
+		policy:   policy,
 	}
 }
 
@@ -40,10 +36,9 @@ func (d *ConsensusRetryPolicyDelegator) GetEpochLength(
 	opts *bind.CallOpts,
 ) (*big.Int, error) {
 
-	return retry.CallFunctionWithRetryPolicy(d.delegate.GetEpochLength,
+	return retry.CallFunctionWithPolicy(d.delegate.GetEpochLength,
 		opts,
-		d.maxRetries,
-		d.delayBetweenCalls,
+		d.policy,
 		"Consensus::GetEpochLength",
 	)
 
@@ -58,12 +53,11 @@ func (d *ConsensusRetryPolicyDelegator) RetrieveClaimAcceptanceEvents(
 	opts *bind.FilterOpts,
 	appAddresses []common.Address,
 ) ([]*iconsensus.IConsensusClaimAcceptance, error) {
-	return retry.CallFunctionWithRetryPolicy(d.retrieveClaimAcceptanceEvents,
+	return retry.CallFunctionWithPolicy(d.retrieveClaimAcceptanceEvents,
 		retrieveClaimAcceptedEventsArgs{
 			opts:         opts,
 			appAddresses: appAddresses,
-		}, d.maxRetries,
-		d.delayBetweenCalls,
+		}, d.policy,
 		"Consensus::RetrieveClaimAcceptedEvents")
 }
 
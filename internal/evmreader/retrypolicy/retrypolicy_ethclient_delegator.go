@@ -6,7 +6,6 @@ package retrypolicy
 import (
 	"context"
 	"math/big"
-	"time"
 
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
@@ -18,20 +17,17 @@ import (
 // calls HeaderByNumber with the retry
 // policy defined by util.RetryFunction
 type EthClientRetryPolicyDelegator struct {
-	delegate          evmreader.EthClient
-	maxRetries        uint64
-	delayBetweenCalls time.Duration
+	delegate evmreader.EthClient
+	policy   *retry.Policy
 }
 
 func NewEhtClientWithRetryPolicy(
 	delegate evmreader.EthClient,
-	maxRetries uint64,
-	delayBetweenCalls time.Duration,
+	policy *retry.Policy,
 ) *EthClientRetryPolicyDelegator {
 	return &EthClientRetryPolicyDelegator{
-		delegate:          delegate,
-		maxRetries:        maxRetries,
-		delayBetweenCalls: delayBetweenCalls,
+		delegate: delegate,
+		policy:   policy,
 	}
 }
 
@@ -45,13 +41,12 @@ func (d *EthClientRetryPolicyDelegator) HeaderByNumber(
 	number *big.Int,
 ) (*types.Header, error) {
 
-	return retry.CallFunctionWithRetryPolicy(d.headerByNumber,
+	return retry.CallFunctionWithPolicy(d.headerByNumber,
 		headerByNumberArgs{
 			ctx:    ctx,
 			number: number,
 		},
-		d.maxRetries,
-		d.delayBetweenCalls,
+		d.policy,
 		"EthClient::HeaderByNumber",
 	)
 
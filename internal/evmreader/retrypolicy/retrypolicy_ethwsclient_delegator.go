@@ -5,7 +5,6 @@ package retrypolicy
 
 import (
 	"context"
-	"time"
 
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
@@ -15,20 +14,17 @@ import (
 )
 
 type EthWsClientRetryPolicyDelegator struct {
-	delegate          evmreader.EthWsClient
-	maxRetries        uint64
-	delayBetweenCalls time.Duration
+	delegate evmreader.EthWsClient
+	policy   *retry.Policy
 }
 
 func NewEthWsClientWithRetryPolicy(
 	delegate evmreader.EthWsClient,
-	maxRetries uint64,
-	delayBetweenCalls time.Duration,
+	policy *retry.Policy,
 ) *EthWsClientRetryPolicyDelegator {
 	return &EthWsClientRetryPolicyDelegator{
-		delegate:          delegate,
-		maxRetries:        maxRetries,
-		delayBetweenCalls: delayBetweenCalls,
+		delegate: delegate,
+		policy:   policy,
 	}
 }
 
@@ -42,14 +38,13 @@ func (d *EthWsClientRetryPolicyDelegator) SubscribeNewHead(
 	ch chan<- *types.Header,
 ) (ethereum.Subscription, error) {
 
-	return retry.CallFunctionWithRetryPolicy(
+	return retry.CallFunctionWithPolicy(
 		d.subscribeNewHead,
 		subscribeNewHeadArgs{
 			ctx: ctx,
 			ch:  ch,
 		},
-		d.maxRetries,
-		d.delayBetweenCalls,
+		d.policy,
 		"EthWSClient::SubscribeNewHead",
 	)
 }
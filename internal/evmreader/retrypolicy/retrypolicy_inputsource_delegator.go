@@ -5,7 +5,6 @@ package retrypolicy
 
 import (
 	"math/big"
-	"time"
 
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
@@ -16,20 +15,17 @@ import (
 )
 
 type InputSourceWithRetryPolicyDelegator struct {
-	delegate   evmreader.InputSource
-	maxRetries uint64
-	delay      time.Duration
+	delegate evmreader.InputSource
+	policy   *retry.Policy
 }
 
 func NewInputSourceWithRetryPolicy(
 	delegate evmreader.InputSource,
-	maxRetries uint64,
-	delay time.Duration,
+	policy *retry.Policy,
 ) *InputSourceWithRetryPolicyDelegator {
 	return &InputSourceWithRetryPolicyDelegator{
-		delegate:   delegate,
-		maxRetries: maxRetries,
-		delay:      delay,
+		delegate: delegate,
+		policy:   policy,
 	}
 }
 
@@ -44,14 +40,13 @@ func (d *InputSourceWithRetryPolicyDelegator) RetrieveInputs(
 	appContract []common.Address,
 	index []*big.Int,
 ) ([]iinputbox.IInputBoxInputAdded, error) {
-	return retry.CallFunctionWithRetryPolicy(d.retrieveInputs,
+	return retry.CallFunctionWithPolicy(d.retrieveInputs,
 		retrieveInputsArgs{
 			opts:        opts,
 			appContract: appContract,
 			index:       index,
 		},
-		d.maxRetries,
-		d.delay,
+		d.policy,
 		"InputSource::RetrieveInputs",
 	)
 }
@@ -0,0 +1,41 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
+	"github.com/ZzzzHui/espresso-reader/internal/evmreader/retrypolicy"
+	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DialBlockWatcher picks how the reader watches for new blocks from the scheme of endpoint:
+// it subscribes to them on ws and wss endpoints, and polls client every interval otherwise.
+// The returned function closes the connection it opened, if any.
+func DialBlockWatcher(
+	ctx context.Context,
+	endpoint string,
+	client evmreader.EthClient,
+	policy *retry.Policy,
+	interval time.Duration,
+) (evmreader.BlockWatcher, func(), error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") {
+		slog.Info("evmreader: Polling for new blocks", "interval", interval)
+		return evmreader.NewPollingBlockWatcher(client, interval), func() {}, nil
+	}
+
+	wsClient, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Info("evmreader: Subscribing to new blocks through WebSocket")
+	return retrypolicy.NewEthWsClientWithRetryPolicy(wsClient, policy), wsClient.Close, nil
+}
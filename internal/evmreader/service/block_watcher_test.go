@@ -0,0 +1,37 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
+	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BlockWatcherSuite struct {
+	suite.Suite
+}
+
+func TestBlockWatcherSuite(t *testing.T) {
+	suite.Run(t, new(BlockWatcherSuite))
+}
+
+func (s *BlockWatcherSuite) TestItPicksTheWatcherFromTheScheme() {
+	policy := retry.NewPolicy(0, 0)
+	for _, endpoint := range []string{"http://localhost:8545", "https://eth.example/key"} {
+		watcher, close, err := DialBlockWatcher(context.Background(), endpoint, nil, policy, time.Second)
+		s.Require().Nil(err)
+		s.IsType(&evmreader.PollingBlockWatcher{}, watcher, endpoint)
+		close()
+	}
+
+	// a WebSocket endpoint is dialed, to subscribe to new blocks
+	_, _, err := DialBlockWatcher(context.Background(), "ws://127.0.0.1:1", nil, policy, time.Second)
+	s.ErrorContains(err, "connection refused")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader"
 	"github.com/ZzzzHui/espresso-reader/internal/evmreader/retrypolicy"
 	"github.com/ZzzzHui/espresso-reader/internal/repository"
+	"github.com/ZzzzHui/espresso-reader/internal/services/retry"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -18,6 +19,7 @@ import (
 type EvmReaderService struct {
 	blockchainHttpEndpoint string
 	blockchainWsEndpoint   string
+	pollingInterval        time.Duration
 	database               *repository.Database
 	maxRetries             uint64
 	maxDelay               time.Duration
@@ -26,6 +28,7 @@ type EvmReaderService struct {
 func NewEvmReaderService(
 	blockchainHttpEndpoint string,
 	blockchainWsEndpoint string,
+	pollingInterval time.Duration,
 	database *repository.Database,
 	maxRetries uint64,
 	maxDelay time.Duration,
@@ -33,6 +36,7 @@ func NewEvmReaderService(
 	return &EvmReaderService{
 		blockchainHttpEndpoint: blockchainHttpEndpoint,
 		blockchainWsEndpoint:   blockchainWsEndpoint,
+		pollingInterval:        pollingInterval,
 		database:               database,
 		maxRetries:             maxRetries,
 		maxDelay:               maxDelay,
@@ -50,28 +54,35 @@ func (s *EvmReaderService) Start(
 	}
 	defer client.Close()
 
-	wsClient, err := ethclient.DialContext(ctx, s.blockchainWsEndpoint)
+	config, err := s.database.GetNodeConfig(ctx)
 	if err != nil {
 		return err
 	}
-	defer wsClient.Close()
 
-	config, err := s.database.GetNodeConfig(ctx)
+	inputSource, err := evmreader.NewInputSourceAdapter(config.InputBoxAddress, client)
 	if err != nil {
 		return err
 	}
 
-	inputSource, err := evmreader.NewInputSourceAdapter(config.InputBoxAddress, client)
+	policy := retry.NewPolicy(s.maxRetries, s.maxDelay)
+	contractFactory := retrypolicy.NewEvmReaderContractFactory(client, policy)
+	ethClient := retrypolicy.NewEhtClientWithRetryPolicy(client, policy)
+
+	// without a WebSocket endpoint, the reader polls the HTTP one
+	endpoint := s.blockchainWsEndpoint
+	if endpoint == "" {
+		endpoint = s.blockchainHttpEndpoint
+	}
+	blockWatcher, closeBlockWatcher, err := DialBlockWatcher(ctx, endpoint, ethClient, policy, s.pollingInterval)
 	if err != nil {
 		return err
 	}
-
-	contractFactory := retrypolicy.NewEvmReaderContractFactory(client, s.maxRetries, s.maxDelay)
+	defer closeBlockWatcher()
 
 	reader := evmreader.NewEvmReader(
-		retrypolicy.NewEhtClientWithRetryPolicy(client, s.maxRetries, s.maxDelay),
-		retrypolicy.NewEthWsClientWithRetryPolicy(wsClient, s.maxRetries, s.maxDelay),
-		retrypolicy.NewInputSourceWithRetryPolicy(inputSource, s.maxRetries, s.maxDelay),
+		ethClient,
+		blockWatcher,
+		retrypolicy.NewInputSourceWithRetryPolicy(inputSource, policy),
 		s.database,
 		config.InputBoxDeploymentBlock,
 		config.DefaultBlock,
@@ -5,9 +5,15 @@ package evmreader
 
 import (
 	"cmp"
+	"context"
 	"slices"
 
 	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CalculateEpochIndex calculates the epoch index given the input block number
@@ -55,3 +61,20 @@ func indexApps[K comparable](
 	}
 	return result
 }
+
+// startFilterSpan starts the span of a log query, returning the options
+// to run the query with in the context of the span
+func startFilterSpan(opts *bind.FilterOpts, method string) (*bind.FilterOpts, trace.Span) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attrs := []attribute.KeyValue{tracing.AttrFromBlock.Int64(int64(opts.Start))}
+	if opts.End != nil {
+		attrs = append(attrs, tracing.AttrToBlock.Int64(int64(*opts.End)))
+	}
+	ctx, span := tracing.Start(ctx, method, attrs...)
+	spanOpts := *opts
+	spanOpts.Context = ctx
+	return &spanOpts, span
+}
@@ -0,0 +1,120 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package metrics holds the Prometheus metrics of the Espresso and EVM readers,
+// served by the Espresso service at /metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "espresso_reader"
+
+// Sources of the ingested inputs
+const (
+	SourceEspresso = "espresso"
+	SourceInputBox = "inputbox"
+)
+
+// Stages where transactions are rejected
+const (
+	StageService = "service"
+	StageReader  = "reader"
+)
+
+// Registry holds every metric of the reader, along with the Go and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	EspressoLatestHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "espresso_latest_height",
+		Help:      "Latest block height of Espresso.",
+	})
+	EspressoProcessedHeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "espresso_processed_height",
+		Help:      "Espresso block height each application was read up to.",
+	}, []string{"app"})
+	L1FinalizedBlock = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "l1_finalized_block",
+		Help:      "Latest L1 finalized block, as seen by Espresso.",
+	})
+	L1ProcessedBlock = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "l1_processed_block",
+		Help:      "L1 block each application was read up to.",
+	}, []string{"app"})
+	InputsIngested = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inputs_ingested_total",
+		Help:      "Inputs stored, by application and source.",
+	}, []string{"app", "source"})
+	TransactionsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_rejected_total",
+		Help:      "Espresso transactions rejected by the service or skipped by the reader, by reason.",
+	}, []string{"stage", "reason"})
+	RpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Duration of the calls to the blockchain and to Espresso, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "query_duration_seconds",
+		Help:      "Duration of the database queries, by repository method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	Retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retries_total",
+		Help:      "Calls retried by the retry policy, by method.",
+	}, []string{"method"})
+	RetriesExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retries_exhausted_total",
+		Help:      "Calls that still failed after the last retry, by method.",
+	}, []string{"method"})
+	ServiceRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "service_requests_total",
+		Help:      "Requests to the Espresso service API, such as /nonce, by path and status code.",
+	}, []string{"path", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		EspressoLatestHeight,
+		EspressoProcessedHeight,
+		L1FinalizedBlock,
+		L1ProcessedBlock,
+		InputsIngested,
+		TransactionsRejected,
+		RpcDuration,
+		QueryDuration,
+		Retries,
+		RetriesExhausted,
+		ServiceRequests,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRpc records the duration of a call that started at start
+func ObserveRpc(method string, start time.Time) {
+	RpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
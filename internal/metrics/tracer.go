@@ -0,0 +1,51 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryTracer is a pgx.QueryTracer that records the duration of the queries,
+// labeled by the repository method set in their context with WithMethod
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+type methodKey struct{}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	method string
+	time   time.Time
+}
+
+// WithMethod returns a context whose queries are labeled with the repository method
+func WithMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, methodKey{}, method)
+}
+
+func (QueryTracer) TraceQueryStart(
+	ctx context.Context,
+	conn *pgx.Conn,
+	data pgx.TraceQueryStartData,
+) context.Context {
+	method, ok := ctx.Value(methodKey{}).(string)
+	if !ok {
+		method = "unknown"
+	}
+	return context.WithValue(ctx, queryStartKey{}, queryStart{method: method, time: time.Now()})
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	QueryDuration.WithLabelValues(start.method).Observe(time.Since(start.time).Seconds())
+}
@@ -0,0 +1,39 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type TracerSuite struct {
+	suite.Suite
+}
+
+func TestTracerSuite(t *testing.T) {
+	suite.Run(t, new(TracerSuite))
+}
+
+func (s *TracerSuite) trace(ctx context.Context) {
+	tracer := QueryTracer{}
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+}
+
+func (s *TracerSuite) TestItLabelsQueriesWithTheirMethod() {
+	QueryDuration.Reset()
+	s.trace(WithMethod(context.Background(), "GetInput"))
+	s.True(QueryDuration.DeleteLabelValues("GetInput"))
+	s.False(QueryDuration.DeleteLabelValues("unknown"))
+}
+
+func (s *TracerSuite) TestItLabelsQueriesWithoutAMethodAsUnknown() {
+	QueryDuration.Reset()
+	s.trace(context.Background())
+	s.True(QueryDuration.DeleteLabelValues("unknown"))
+}
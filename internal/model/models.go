@@ -4,6 +4,7 @@
 package model
 
 import (
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -20,6 +21,7 @@ type (
 	ApplicationStatus     string
 	DefaultBlock          string
 	EpochStatus           string
+	OutputType            string
 )
 
 const (
@@ -56,6 +58,12 @@ const (
 	EpochStatusClaimRejected      EpochStatus = "CLAIM_REJECTED"
 )
 
+const (
+	OutputTypeVoucher             OutputType = "VOUCHER"
+	OutputTypeNotice              OutputType = "NOTICE"
+	OutputTypeDelegateCallVoucher OutputType = "DELEGATE_CALL_VOUCHER"
+)
+
 type NodePersistentConfig struct {
 	DefaultBlock            DefaultBlock
 	InputBoxDeploymentBlock uint64
@@ -129,6 +137,11 @@ type Output struct {
 	OutputHashesSiblings []Hash
 	InputId              uint64
 	TransactionHash      *Hash
+	// Decoded from RawData by the database, nil if RawData is not a valid output
+	Type        *OutputType
+	Destination *Address
+	Value       *big.Int
+	Payload     Bytes
 }
 
 type Report struct {
@@ -8,13 +8,17 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
 	"sync"
 	"time"
 
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
 	. "github.com/ZzzzHui/espresso-reader/internal/model"
 	"github.com/ZzzzHui/espresso-reader/internal/repository/schema"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -29,8 +33,14 @@ var (
 
 	ErrBeginTx  = errors.New("unable to begin transaction")
 	ErrCommitTx = errors.New("unable to commit transaction")
+
+	ErrApplicationNotFound = errors.New("application not found")
+	ErrApplicationInUse    = errors.New("application has epochs or inputs")
 )
 
+// SQLSTATE of foreign key constraint violations
+const foreignKeyViolation = "23503"
+
 func ValidateSchema(endpoint string) error {
 
 	schema, err := schema.New(endpoint)
@@ -51,6 +61,10 @@ func ValidateSchemaWithRetry(endpoint string, maxRetries int, delay time.Duratio
 		if err == nil {
 			return nil
 		}
+		if errors.Is(err, schema.ErrVersionMismatch) {
+			// retrying won't change the version, only a migration will
+			return err
+		}
 		time.Sleep(delay)
 	}
 	return fmt.Errorf("failed to validate schema after %d attempts: %w", maxRetries, err)
@@ -72,7 +86,13 @@ func Connect(
 	}
 
 	pgOnce.Do(func() {
-		dbpool, err := pgxpool.New(ctx, postgresEndpoint)
+		config, err := pgxpool.ParseConfig(postgresEndpoint)
+		if err != nil {
+			pgError = fmt.Errorf("unable to parse the postgres endpoint: %w\n", err)
+			return
+		}
+		config.ConnConfig.Tracer = metrics.QueryTracer{}
+		dbpool, err := pgxpool.NewWithConfig(ctx, config)
 		if err != nil {
 			pgError = fmt.Errorf("unable to create connection pool: %w\n", err)
 			return
@@ -84,6 +104,28 @@ func Connect(
 	return pgInstance, pgError
 }
 
+// CheckSchema fails unless the schema of the database is the one the node expects.
+// Unlike ValidateSchema, it uses the connection pool, so it is cheap enough for health checks.
+func (pg *Database) CheckSchema(ctx context.Context) error {
+	ctx = metrics.WithMethod(ctx, "CheckSchema")
+	var (
+		version uint
+		dirty   bool
+	)
+	err := pg.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("failed to read the schema version: %w", err)
+	}
+	if version != schema.ExpectedVersion {
+		return fmt.Errorf("%w. Expected %d but it is %d",
+			schema.ErrVersionMismatch, schema.ExpectedVersion, version)
+	}
+	if dirty {
+		return fmt.Errorf("Database schema version %d is dirty, a migration failed halfway", version)
+	}
+	return nil
+}
+
 func (pg *Database) GetDB() *pgxpool.Pool {
 	return pg.db
 }
@@ -98,6 +140,7 @@ func (pg *Database) InsertNodeConfig(
 	ctx context.Context,
 	config *NodePersistentConfig,
 ) error {
+	ctx = metrics.WithMethod(ctx, "InsertNodeConfig")
 	query := `
 	INSERT INTO node_config
 		(default_block,
@@ -126,10 +169,53 @@ func (pg *Database) InsertNodeConfig(
 	return nil
 }
 
+// UpdateNodeConfig replaces the persistent config of the node,
+// inserting it if there is none
+func (pg *Database) UpdateNodeConfig(
+	ctx context.Context,
+	config *NodePersistentConfig,
+) error {
+	ctx = metrics.WithMethod(ctx, "UpdateNodeConfig")
+	tx, err := pg.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUpdateRow, err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM node_config`); err != nil {
+		return errors.Join(ErrUpdateRow, err, tx.Rollback(ctx))
+	}
+	query := `
+	INSERT INTO node_config
+		(default_block,
+		input_box_deployment_block,
+		input_box_address,
+		chain_id)
+	VALUES
+		(@defaultBlock,
+		@deploymentBlock,
+		@inputBoxAddress,
+		@chainId)`
+
+	args := pgx.NamedArgs{
+		"defaultBlock":    config.DefaultBlock,
+		"deploymentBlock": config.InputBoxDeploymentBlock,
+		"inputBoxAddress": config.InputBoxAddress,
+		"chainId":         config.ChainId,
+	}
+	if _, err := tx.Exec(ctx, query, args); err != nil {
+		return errors.Join(ErrUpdateRow, err, tx.Rollback(ctx))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Join(ErrCommitTx, err, tx.Rollback(ctx))
+	}
+	return nil
+}
+
 func (pg *Database) InsertApplication(
 	ctx context.Context,
 	app *Application,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "InsertApplication")
 	query := `
 	INSERT INTO application
 		(contract_address,
@@ -199,6 +285,7 @@ func (pg *Database) InsertEpoch(
 	ctx context.Context,
 	epoch *Epoch,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "InsertEpoch")
 
 	var id uint64
 
@@ -244,6 +331,7 @@ func (pg *Database) InsertInput(
 	ctx context.Context,
 	input *Input,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "InsertInput")
 	query := `
 	INSERT INTO input
 		(index,
@@ -291,6 +379,7 @@ func (pg *Database) InsertOutput(
 	ctx context.Context,
 	output *Output,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "InsertOutput")
 	query := `
 	INSERT INTO output
 		(index,
@@ -332,6 +421,7 @@ func (pg *Database) InsertReport(
 	ctx context.Context,
 	report *Report,
 ) error {
+	ctx = metrics.WithMethod(ctx, "InsertReport")
 	query := `
 	INSERT INTO report
 		(index,
@@ -360,6 +450,7 @@ func (pg *Database) InsertSnapshot(
 	ctx context.Context,
 	snapshot *Snapshot,
 ) (id uint64, _ error) {
+	ctx = metrics.WithMethod(ctx, "InsertSnapshot")
 	query := `
 	INSERT INTO snapshot
 		(input_id,
@@ -390,6 +481,7 @@ func (pg *Database) InsertSnapshot(
 func (pg *Database) GetNodeConfig(
 	ctx context.Context,
 ) (*NodePersistentConfig, error) {
+	ctx = metrics.WithMethod(ctx, "GetNodeConfig")
 	var (
 		defaultBlock    DefaultBlock
 		deploymentBlock uint64
@@ -430,6 +522,7 @@ func (pg *Database) GetApplication(
 	ctx context.Context,
 	appAddressKey Address,
 ) (*Application, error) {
+	ctx = metrics.WithMethod(ctx, "GetApplication")
 	var (
 		id                   uint64
 		contractAddress      Address
@@ -503,6 +596,7 @@ func (pg *Database) UpdateApplicationStatus(
 	appAddressKey Address,
 	newStatus ApplicationStatus,
 ) error {
+	ctx = metrics.WithMethod(ctx, "UpdateApplicationStatus")
 	query := `
 	UPDATE
 		application
@@ -531,7 +625,59 @@ func (pg *Database) UpdateApplicationStatus(
 	return nil
 }
 
+// DeleteApplication removes an application along with its execution parameters,
+// Espresso nonces and input index. It fails with ErrApplicationInUse if the
+// application already has epochs, inputs or snapshots.
+func (pg *Database) DeleteApplication(
+	ctx context.Context,
+	appAddressKey Address,
+) error {
+	ctx = metrics.WithMethod(ctx, "DeleteApplication")
+	args := pgx.NamedArgs{"contractAddress": appAddressKey}
+	queries := []string{`
+	DELETE FROM
+		execution_parameters
+	WHERE
+		application_id IN (SELECT id FROM application WHERE contract_address=@contractAddress)`,
+		`DELETE FROM espresso_nonce WHERE application_address=@contractAddress`,
+		`DELETE FROM input_index WHERE application_address=@contractAddress`,
+	}
+
+	tx, err := pg.db.Begin(ctx)
+	if err != nil {
+		return errors.Join(ErrBeginTx, err)
+	}
+
+	for _, query := range queries {
+		_, err = tx.Exec(ctx, query, args)
+		if err != nil {
+			return errors.Join(err, tx.Rollback(ctx))
+		}
+	}
+
+	commandTag, err := tx.Exec(ctx,
+		`DELETE FROM application WHERE contract_address=@contractAddress`, args)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			err = ErrApplicationInUse
+		}
+		return errors.Join(err, tx.Rollback(ctx))
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errors.Join(ErrApplicationNotFound, tx.Rollback(ctx))
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Join(ErrCommitTx, err, tx.Rollback(ctx))
+	}
+
+	return nil
+}
+
 func (pg *Database) GetEpochs(ctx context.Context, application Address) ([]Epoch, error) {
+	ctx = metrics.WithMethod(ctx, "GetEpochs")
 	query := `
 	SELECT
 		id,
@@ -594,6 +740,7 @@ func (pg *Database) GetEpoch(
 	indexKey uint64,
 	appAddressKey Address,
 ) (*Epoch, error) {
+	ctx = metrics.WithMethod(ctx, "GetEpoch")
 	var (
 		id                 uint64
 		index              uint64
@@ -661,10 +808,77 @@ func (pg *Database) GetEpoch(
 
 }
 
+// GetOpenEpoch returns the latest open epoch of the application, or nil if it has none
+func (pg *Database) GetOpenEpoch(ctx context.Context, appAddressKey Address) (*Epoch, error) {
+	ctx = metrics.WithMethod(ctx, "GetOpenEpoch")
+	var (
+		id                 uint64
+		index              uint64
+		firstBlock         uint64
+		lastBlock          uint64
+		transactionHash    *Hash
+		claimHash          *Hash
+		status             EpochStatus
+		applicationAddress Address
+	)
+
+	query := `
+	SELECT
+		id,
+		index,
+		first_block,
+		last_block,
+		transaction_hash,
+		claim_hash,
+		status,
+		application_address
+	FROM
+		epoch
+	WHERE
+		application_address=@appAddress AND status=@status
+	ORDER BY
+		index DESC
+	LIMIT 1`
+
+	args := pgx.NamedArgs{
+		"appAddress": appAddressKey,
+		"status":     EpochStatusOpen,
+	}
+
+	err := pg.db.QueryRow(ctx, query, args).Scan(
+		&id,
+		&index,
+		&firstBlock,
+		&lastBlock,
+		&transactionHash,
+		&claimHash,
+		&status,
+		&applicationAddress,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetOpenEpoch QueryRow failed: %w", err)
+	}
+
+	return &Epoch{
+		Id:              id,
+		Index:           index,
+		FirstBlock:      firstBlock,
+		LastBlock:       lastBlock,
+		TransactionHash: transactionHash,
+		ClaimHash:       claimHash,
+		Status:          status,
+		AppAddress:      applicationAddress,
+	}, nil
+}
+
 func (pg *Database) GetInputs(
 	ctx context.Context,
 	app Address,
 ) ([]*Input, error) {
+	ctx = metrics.WithMethod(ctx, "GetInputs")
 	query := `
 	SELECT   id, index, status, raw_data, epoch_id
 	FROM     input
@@ -699,6 +913,7 @@ func (pg *Database) GetInput(
 	appAddressKey Address,
 	indexKey uint64,
 ) (*Input, error) {
+	ctx = metrics.WithMethod(ctx, "GetInput")
 	var (
 		id          uint64
 		index       uint64
@@ -709,6 +924,7 @@ func (pg *Database) GetInput(
 		outputsHash *Hash
 		appAddress  Address
 		epochId     uint64
+		txId        []byte
 	)
 
 	query := `
@@ -721,7 +937,8 @@ func (pg *Database) GetInput(
 		machine_hash,
 		outputs_hash,
 		application_address,
-		epoch_id
+		epoch_id,
+		transaction_id
 	FROM
 		input
 	WHERE
@@ -742,6 +959,7 @@ func (pg *Database) GetInput(
 		&outputsHash,
 		&appAddress,
 		&epochId,
+		&txId,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -764,15 +982,76 @@ func (pg *Database) GetInput(
 		OutputsHash:      outputsHash,
 		AppAddress:       appAddress,
 		EpochId:          epochId,
+		TransactionId:    txId,
+	}
+
+	return &input, nil
+}
+
+// GetInputByTransactionId returns the input with the given transaction id,
+// or nil if no such input was read yet
+func (pg *Database) GetInputByTransactionId(
+	ctx context.Context,
+	transactionId []byte,
+) (*Input, error) {
+	ctx = metrics.WithMethod(ctx, "GetInputByTransactionId")
+	var input Input
+
+	query := `
+	SELECT
+		id,
+		index,
+		raw_data,
+		status,
+		block_number,
+		machine_hash,
+		outputs_hash,
+		application_address,
+		epoch_id,
+		transaction_id
+	FROM
+		input
+	WHERE
+		transaction_id=@transactionId`
+
+	args := pgx.NamedArgs{
+		"transactionId": transactionId,
+	}
+
+	err := pg.db.QueryRow(ctx, query, args).Scan(
+		&input.Id,
+		&input.Index,
+		&input.RawData,
+		&input.CompletionStatus,
+		&input.BlockNumber,
+		&input.MachineHash,
+		&input.OutputsHash,
+		&input.AppAddress,
+		&input.EpochId,
+		&input.TransactionId,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			slog.Debug("GetInputByTransactionId returned no rows",
+				"service", "repository",
+				"transactionId", transactionId)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetInputByTransactionId QueryRow failed: %w\n", err)
 	}
 
 	return &input, nil
 }
 
+// GetOutputs returns the outputs of an application.
+// A nil typeFilter or destinationFilter matches the outputs of any type or destination.
 func (pg *Database) GetOutputs(
 	ctx context.Context,
 	application Address,
+	typeFilter *OutputType,
+	destinationFilter *Address,
 ) ([]Output, error) {
+	ctx = metrics.WithMethod(ctx, "GetOutputs")
 	query := `
 	SELECT
 		o.id,
@@ -780,7 +1059,11 @@ func (pg *Database) GetOutputs(
 		o.raw_data,
 		o.hash,
 		o.output_hashes_siblings,
-		o.input_id
+		o.input_id,
+		o.type,
+		o.destination,
+		o.value,
+		o.payload
 	FROM
 		output o
 	INNER JOIN
@@ -789,11 +1072,19 @@ func (pg *Database) GetOutputs(
 		o.input_id=i.id
 	WHERE
 		i.application_address=@appAddress
+	AND
+		(@outputType::TEXT IS NULL OR o.type::TEXT=@outputType)
+	AND
+		(@destination::BYTEA IS NULL OR o.destination=@destination)
 	ORDER BY
 		o.index ASC
 	`
 
-	args := pgx.NamedArgs{"appAddress": application}
+	args := pgx.NamedArgs{
+		"appAddress":  application,
+		"outputType":  typeFilter,
+		"destination": destinationFilter,
+	}
 	rows, err := pg.db.Query(ctx, query, args)
 	if err != nil {
 		return nil, fmt.Errorf("GetOutputs failed: %w", err)
@@ -804,9 +1095,14 @@ func (pg *Database) GetOutputs(
 		rawData              []byte
 		hash                 *Hash
 		outputHashesSiblings []Hash
+		outputType           *OutputType
+		destination          *Address
+		value                pgtype.Numeric
+		payload              []byte
 		outputs              []Output
 	)
-	scans := []any{&id, &index, &rawData, &hash, &outputHashesSiblings, &inputId}
+	scans := []any{&id, &index, &rawData, &hash, &outputHashesSiblings, &inputId,
+		&outputType, &destination, &value, &payload}
 	_, err = pgx.ForEachRow(rows, scans, func() error {
 		output := Output{
 			Id:                   id,
@@ -815,6 +1111,10 @@ func (pg *Database) GetOutputs(
 			Hash:                 hash,
 			OutputHashesSiblings: outputHashesSiblings,
 			InputId:              inputId,
+			Type:                 outputType,
+			Destination:          destination,
+			Value:                bigIntFromNumeric(value),
+			Payload:              payload,
 		}
 		outputs = append(outputs, output)
 		return nil
@@ -825,11 +1125,16 @@ func (pg *Database) GetOutputs(
 	return outputs, nil
 }
 
+// GetOutputsByInputIndex returns the outputs of an input of an application.
+// A nil typeFilter or destinationFilter matches the outputs of any type or destination.
 func (pg *Database) GetOutputsByInputIndex(
 	ctx context.Context,
 	application Address,
 	inputIndex uint64,
+	typeFilter *OutputType,
+	destinationFilter *Address,
 ) ([]Output, error) {
+	ctx = metrics.WithMethod(ctx, "GetOutputsByInputIndex")
 	query := `
 	SELECT
 		o.id,
@@ -837,7 +1142,11 @@ func (pg *Database) GetOutputsByInputIndex(
 		o.raw_data,
 		o.hash,
 		o.output_hashes_siblings,
-		o.input_id
+		o.input_id,
+		o.type,
+		o.destination,
+		o.value,
+		o.payload
 	FROM
 		output o
 	INNER JOIN
@@ -848,13 +1157,19 @@ func (pg *Database) GetOutputsByInputIndex(
 		i.application_address=@appAddress
 	AND
 		i.index=@inputIndex
+	AND
+		(@outputType::TEXT IS NULL OR o.type::TEXT=@outputType)
+	AND
+		(@destination::BYTEA IS NULL OR o.destination=@destination)
 	ORDER BY
 		o.index ASC
 	`
 
 	args := pgx.NamedArgs{
-		"appAddress": application,
-		"inputIndex": inputIndex,
+		"appAddress":  application,
+		"inputIndex":  inputIndex,
+		"outputType":  typeFilter,
+		"destination": destinationFilter,
 	}
 	rows, err := pg.db.Query(ctx, query, args)
 	if err != nil {
@@ -866,9 +1181,14 @@ func (pg *Database) GetOutputsByInputIndex(
 		rawData              []byte
 		hash                 *Hash
 		outputHashesSiblings []Hash
+		outputType           *OutputType
+		destination          *Address
+		value                pgtype.Numeric
+		payload              []byte
 		outputs              []Output
 	)
-	scans := []any{&id, &index, &rawData, &hash, &outputHashesSiblings, &inputId}
+	scans := []any{&id, &index, &rawData, &hash, &outputHashesSiblings, &inputId,
+		&outputType, &destination, &value, &payload}
 	_, err = pgx.ForEachRow(rows, scans, func() error {
 		output := Output{
 			Id:                   id,
@@ -877,6 +1197,10 @@ func (pg *Database) GetOutputsByInputIndex(
 			Hash:                 hash,
 			OutputHashesSiblings: outputHashesSiblings,
 			InputId:              inputId,
+			Type:                 outputType,
+			Destination:          destination,
+			Value:                bigIntFromNumeric(value),
+			Payload:              payload,
 		}
 		outputs = append(outputs, output)
 		return nil
@@ -892,6 +1216,7 @@ func (pg *Database) GetOutput(
 	appAddressKey Address,
 	indexKey uint64,
 ) (*Output, error) {
+	ctx = metrics.WithMethod(ctx, "GetOutput")
 	var (
 		id                   uint64
 		index                uint64
@@ -900,6 +1225,10 @@ func (pg *Database) GetOutput(
 		outputHashesSiblings []Hash
 		inputId              uint64
 		transactionHash      *Hash
+		outputType           *OutputType
+		destination          *Address
+		value                pgtype.Numeric
+		payload              []byte
 	)
 
 	query := `
@@ -910,7 +1239,11 @@ func (pg *Database) GetOutput(
 		o.hash,
 		o.output_hashes_siblings,
 		o.input_id,
-		o.transaction_hash
+		o.transaction_hash,
+		o.type,
+		o.destination,
+		o.value,
+		o.payload
 	FROM
 		output o
 	INNER JOIN
@@ -933,6 +1266,10 @@ func (pg *Database) GetOutput(
 		&outputHashesSiblings,
 		&inputId,
 		&transactionHash,
+		&outputType,
+		&destination,
+		&value,
+		&payload,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -953,15 +1290,35 @@ func (pg *Database) GetOutput(
 		OutputHashesSiblings: outputHashesSiblings,
 		InputId:              inputId,
 		TransactionHash:      transactionHash,
+		Type:                 outputType,
+		Destination:          destination,
+		Value:                bigIntFromNumeric(value),
+		Payload:              payload,
 	}
 
 	return &output, nil
 }
 
+// bigIntFromNumeric converts an integer NUMERIC, returning nil if it is NULL
+func bigIntFromNumeric(n pgtype.Numeric) *big.Int {
+	if !n.Valid || n.Int == nil {
+		return nil
+	}
+	value := new(big.Int).Set(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(n.Exp, -n.Exp))), nil)
+	if n.Exp > 0 {
+		value.Mul(value, exp)
+	} else if n.Exp < 0 {
+		value.Quo(value, exp)
+	}
+	return value
+}
+
 func (pg *Database) GetReports(
 	ctx context.Context,
 	appAddressKey Address,
 ) ([]Report, error) {
+	ctx = metrics.WithMethod(ctx, "GetReports")
 	var (
 		id      uint64
 		index   uint64
@@ -1018,6 +1375,7 @@ func (pg *Database) GetReportsByInputIndex(
 	appAddressKey Address,
 	inputIndex uint64,
 ) ([]Report, error) {
+	ctx = metrics.WithMethod(ctx, "GetReportsByInputIndex")
 	var (
 		id      uint64
 		index   uint64
@@ -1077,6 +1435,7 @@ func (pg *Database) GetReport(
 	appAddressKey Address,
 	indexKey uint64,
 ) (*Report, error) {
+	ctx = metrics.WithMethod(ctx, "GetReport")
 	var (
 		id      uint64
 		index   uint64
@@ -1134,6 +1493,7 @@ func (pg *Database) GetSnapshot(
 	inputIndexKey uint64,
 	appAddressKey Address,
 ) (*Snapshot, error) {
+	ctx = metrics.WithMethod(ctx, "GetSnapshot")
 	var (
 		id         uint64
 		inputId    uint64
@@ -1193,6 +1553,7 @@ func (pg *Database) GetEspressoNonce(
 	senderAddress Address,
 	applicationAddress Address,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "GetEspressoNonce")
 	var (
 		nonce uint64
 	)
@@ -1230,6 +1591,7 @@ func (pg *Database) UpdateEspressoNonce(
 	senderAddress Address,
 	applicationAddress Address,
 ) error {
+	ctx = metrics.WithMethod(ctx, "UpdateEspressoNonce")
 	nonce, err := pg.GetEspressoNonce(ctx, senderAddress, applicationAddress)
 	if err != nil {
 		return err
@@ -1267,6 +1629,7 @@ func (pg *Database) GetInputIndex(
 	ctx context.Context,
 	applicationAddress Address,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "GetInputIndex")
 	var (
 		index uint64
 	)
@@ -1301,6 +1664,7 @@ func (pg *Database) UpdateInputIndex(
 	ctx context.Context,
 	applicationAddress Address,
 ) error {
+	ctx = metrics.WithMethod(ctx, "UpdateInputIndex")
 	slog.Debug("Updating input index")
 	index, err := pg.GetInputIndex(ctx, applicationAddress)
 	if err != nil {
@@ -5,11 +5,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"math"
+	"math/big"
 	"testing"
 	"time"
 
 	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/pkg/contracts/outputs"
 	"github.com/ZzzzHui/espresso-reader/test/tooling/db"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -31,6 +34,9 @@ func (s *RepositorySuite) SetupSuite() {
 
 	var err error
 	endpoint, err := db.GetPostgresTestEndpoint()
+	if errors.Is(err, db.ErrNoTestDatabase) {
+		s.T().Skip(err)
+	}
 	s.Require().Nil(err)
 
 	err = db.SetupTestPostgres(endpoint)
@@ -230,6 +236,10 @@ func (s *RepositorySuite) SetupDatabase() {
 	s.Require().Equal(uint64(1), id)
 }
 
+func (s *RepositorySuite) TestCheckSchema() {
+	s.Nil(s.database.CheckSchema(s.ctx))
+}
+
 func (s *RepositorySuite) TestApplicationExists() {
 	app := Application{
 		Id:                 1,
@@ -267,6 +277,33 @@ func (s *RepositorySuite) TestApplicationFailsDuplicateRow() {
 	s.Require().ErrorContains(err, "duplicate key value")
 }
 
+func (s *RepositorySuite) TestDeleteApplication() {
+	app := Application{
+		ContractAddress:   common.HexToAddress("deadbeef0123"),
+		IConsensusAddress: common.HexToAddress("ffffff"),
+		TemplateHash:      common.HexToHash("deadbeef"),
+		TemplateUri:       "path/to/template/uri/0",
+		Status:            ApplicationStatusNotRunning,
+	}
+	_, err := s.database.InsertApplication(s.ctx, &app)
+	s.Require().Nil(err)
+
+	err = s.database.DeleteApplication(s.ctx, app.ContractAddress)
+	s.Require().Nil(err)
+
+	response, err := s.database.GetApplication(s.ctx, app.ContractAddress)
+	s.Require().Nil(err)
+	s.Require().Nil(response)
+
+	err = s.database.DeleteApplication(s.ctx, app.ContractAddress)
+	s.Require().ErrorIs(err, ErrApplicationNotFound)
+}
+
+func (s *RepositorySuite) TestDeleteApplicationFailsWithInputs() {
+	err := s.database.DeleteApplication(s.ctx, common.HexToAddress("deadbeef"))
+	s.Require().ErrorIs(err, ErrApplicationInUse)
+}
+
 func (s *RepositorySuite) TestInputExists() {
 	genericHash := common.HexToHash("deadbeef")
 
@@ -336,6 +373,44 @@ func (s *RepositorySuite) TestOutputExists() {
 	s.Require().Nil(err)
 }
 
+func (s *RepositorySuite) TestGetOutputsDecodesAndFiltersOutputs() {
+	parsedAbi, err := outputs.OutputsMetaData.GetAbi()
+	s.Require().Nil(err)
+	destination := common.HexToAddress("0xfeedbeef")
+	voucherData, err := parsedAbi.Pack("Voucher", destination, big.NewInt(7), []byte{0xca, 0xfe})
+	s.Require().Nil(err)
+	noticeData, err := parsedAbi.Pack("Notice", []byte{0xbe, 0xef})
+	s.Require().Nil(err)
+
+	for index, rawData := range map[uint64][]byte{10: voucherData, 11: noticeData} {
+		_, err = s.database.InsertOutput(s.ctx, &Output{Index: index, InputId: 1, RawData: rawData})
+		s.Require().Nil(err)
+	}
+
+	app := common.HexToAddress("deadbeef")
+	voucher := OutputTypeVoucher
+	vouchers, err := s.database.GetOutputs(s.ctx, app, &voucher, nil)
+	s.Require().Nil(err)
+	s.Require().Len(vouchers, 1)
+	s.Equal(uint64(10), vouchers[0].Index)
+	s.Equal(&destination, vouchers[0].Destination)
+	s.Equal("7", vouchers[0].Value.String())
+	s.Equal([]byte{0xca, 0xfe}, vouchers[0].Payload)
+
+	notice := OutputTypeNotice
+	notices, err := s.database.GetOutputsByInputIndex(s.ctx, app, 1, &notice, nil)
+	s.Require().Nil(err)
+	s.Require().Len(notices, 1)
+	s.Equal(uint64(11), notices[0].Index)
+	s.Nil(notices[0].Destination)
+	s.Equal([]byte{0xbe, 0xef}, notices[0].Payload)
+
+	sent, err := s.database.GetOutputs(s.ctx, app, nil, &destination)
+	s.Require().Nil(err)
+	s.Require().Len(sent, 1)
+	s.Equal(uint64(10), sent[0].Index)
+}
+
 func (s *RepositorySuite) TestOutputDoesntExist() {
 	response, err := s.database.GetOutput(s.ctx, common.HexToAddress("deadbeef"), 10)
 	s.Require().Nil(response)
@@ -408,6 +483,18 @@ func (s *RepositorySuite) TestEpochDoesntExist() {
 	s.Require().Nil(err)
 }
 
+func (s *RepositorySuite) TestGetOpenEpoch() {
+	response, err := s.database.GetOpenEpoch(s.ctx, common.HexToAddress("deadbeef"))
+	s.Require().Nil(err)
+	s.Require().NotNil(response)
+	s.Equal(uint64(1), response.Index)
+	s.Equal(EpochStatusOpen, response.Status)
+
+	response, err = s.database.GetOpenEpoch(s.ctx, common.HexToAddress("feadbeef"))
+	s.Require().Nil(err)
+	s.Nil(response)
+}
+
 func (s *RepositorySuite) TestEpochFailsDuplicateRow() {
 	epoch := Epoch{
 		Status:          EpochStatusOpen,
@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
 	. "github.com/ZzzzHui/espresso-reader/internal/model"
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -28,7 +30,12 @@ func (pg *Database) StoreEpochAndInputsTransaction(
 	epochInputsMap map[*Epoch][]Input,
 	blockNumber uint64,
 	contractAddress Address,
-) (epochIndexIdMap map[uint64]uint64, epochIndexInputIdsMap map[uint64][]uint64, _ error) {
+) (epochIndexIdMap map[uint64]uint64, epochIndexInputIdsMap map[uint64][]uint64, err error) {
+	ctx = metrics.WithMethod(ctx, "StoreEpochAndInputsTransaction")
+	ctx, span := tracing.Start(ctx, "Repository::StoreEpochAndInputsTransaction",
+		tracing.AttrApp.String(contractAddress.Hex()),
+		tracing.AttrL1Block.Int64(int64(blockNumber)))
+	defer func() { tracing.End(span, err) }()
 
 	insertEpochQuery := `
 	INSERT INTO epoch
@@ -167,6 +174,7 @@ func (pg *Database) getAllApplicationsByStatus(
 	ctx context.Context,
 	criteria *ApplicationStatus,
 ) ([]Application, error) {
+	ctx = metrics.WithMethod(ctx, "getAllApplicationsByStatus")
 	var (
 		id                   uint64
 		contractAddress      Address
@@ -235,6 +243,7 @@ func (pg *Database) GetLastProcessedBlock(
 	ctx context.Context,
 	appAddress Address,
 ) (uint64, error) {
+	ctx = metrics.WithMethod(ctx, "GetLastProcessedBlock")
 	var block uint64
 
 	query := `
@@ -264,6 +273,7 @@ func (pg *Database) GetPreviousEpochsWithOpenClaims(
 	app Address,
 	block uint64,
 ) ([]*Epoch, error) {
+	ctx = metrics.WithMethod(ctx, "GetPreviousEpochsWithOpenClaims")
 	query := `
 	SELECT
 		id,
@@ -331,6 +341,7 @@ func (pg *Database) UpdateEpochs(
 	claims []*Epoch,
 	lastClaimCheckBlock uint64,
 ) error {
+	ctx = metrics.WithMethod(ctx, "UpdateEpochs")
 
 	updateEpochQuery := `
 	UPDATE epoch
@@ -394,6 +405,7 @@ func (pg *Database) UpdateOutputExecutionTransaction(
 	executedOutputs []*Output,
 	blockNumber uint64,
 ) error {
+	ctx = metrics.WithMethod(ctx, "UpdateOutputExecutionTransaction")
 
 	var errUpdateOutputs = errors.New("unable to update outputs")
 
@@ -0,0 +1,21 @@
+-- (c) Cartesi and individual authors (see AUTHORS)
+-- SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+DROP VIEW IF EXISTS graphql."espresso_blocks";
+
+DROP INDEX IF EXISTS "input_transaction_id_idx";
+
+ALTER TABLE "application" ADD COLUMN "last_processed_espresso_block" NUMERIC(20,0) NOT NULL DEFAULT 0 CHECK ("last_processed_espresso_block" >= 0 AND "last_processed_espresso_block" <= f_maxuint64());
+
+UPDATE
+    "application" a
+SET
+    "last_processed_espresso_block" = b."last_processed_espresso_block"
+FROM
+    "espresso_block" b
+WHERE
+    a."contract_address" = b."application_address";
+
+ALTER TABLE "application" ALTER COLUMN "last_processed_espresso_block" DROP DEFAULT;
+
+DROP TABLE IF EXISTS "espresso_block";
@@ -0,0 +1,35 @@
+-- (c) Cartesi and individual authors (see AUTHORS)
+-- SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+-- Older versions of the reader created this table on start, outside migrations
+CREATE TABLE IF NOT EXISTS "espresso_block"
+(
+    "application_address" BYTEA PRIMARY KEY,
+    "last_processed_espresso_block" NUMERIC(20,0) NOT NULL CHECK ("last_processed_espresso_block" >= 0 AND "last_processed_espresso_block" <= f_maxuint64())
+);
+
+-- application.last_processed_espresso_block was never read, but keep whatever it holds
+INSERT INTO "espresso_block"
+    ("application_address", "last_processed_espresso_block")
+SELECT
+    "contract_address",
+    "last_processed_espresso_block"
+FROM
+    "application"
+WHERE
+    "last_processed_espresso_block" > 0
+ON CONFLICT ("application_address") DO UPDATE
+    SET "last_processed_espresso_block" = GREATEST(
+        "espresso_block"."last_processed_espresso_block",
+        EXCLUDED."last_processed_espresso_block");
+
+ALTER TABLE "application" DROP COLUMN "last_processed_espresso_block";
+
+CREATE INDEX IF NOT EXISTS "input_transaction_id_idx" ON "input"("transaction_id");
+
+CREATE OR REPLACE VIEW graphql."espresso_blocks" AS
+    SELECT
+        "application_address",
+        "last_processed_espresso_block"
+    FROM
+        "espresso_block";
@@ -0,0 +1,34 @@
+-- (c) Cartesi and individual authors (see AUTHORS)
+-- SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+DROP VIEW IF EXISTS graphql."outputs";
+
+CREATE VIEW graphql."outputs" AS
+    SELECT
+        o."index",
+        o."raw_data",
+        o."output_hashes_siblings",
+        o."transaction_hash",
+        i."index" as "input_index"
+    FROM
+        "output" o
+    INNER JOIN
+        "input" i on o."input_id"=i."id";
+
+DROP INDEX IF EXISTS "output_destination_idx";
+DROP INDEX IF EXISTS "output_type_idx";
+
+ALTER TABLE "output"
+    DROP COLUMN "payload",
+    DROP COLUMN "value",
+    DROP COLUMN "destination",
+    DROP COLUMN "type";
+
+DROP FUNCTION IF EXISTS f_output_payload(BYTEA);
+DROP FUNCTION IF EXISTS f_output_value(BYTEA);
+DROP FUNCTION IF EXISTS f_output_destination(BYTEA);
+DROP FUNCTION IF EXISTS f_output_type(BYTEA);
+DROP FUNCTION IF EXISTS f_abi_bytes(BYTEA, BIGINT);
+DROP FUNCTION IF EXISTS f_abi_uint(BYTEA, BIGINT);
+
+DROP TYPE IF EXISTS "OutputType";
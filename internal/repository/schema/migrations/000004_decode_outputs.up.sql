@@ -0,0 +1,103 @@
+-- (c) Cartesi and individual authors (see AUTHORS)
+-- SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+CREATE TYPE "OutputType" AS ENUM ('VOUCHER', 'NOTICE', 'DELEGATE_CALL_VOUCHER');
+
+-- The unsigned integer in the 32-byte ABI word at the offset, or NULL if out of bounds
+CREATE FUNCTION f_abi_uint(data BYTEA, word_offset BIGINT)
+    RETURNS NUMERIC
+    LANGUAGE plpgsql IMMUTABLE PARALLEL SAFE AS $$
+DECLARE
+    result NUMERIC := 0;
+BEGIN
+    IF word_offset IS NULL OR word_offset < 0 OR length(data) < word_offset + 32 THEN
+        RETURN NULL;
+    END IF;
+    FOR i IN word_offset..word_offset + 31 LOOP
+        result := result * 256 + get_byte(data, i::INT);
+    END LOOP;
+    RETURN result;
+END $$;
+
+-- The dynamic bytes argument whose offset is in the ABI word at head_offset, or NULL if out of bounds
+CREATE FUNCTION f_abi_bytes(args BYTEA, head_offset BIGINT)
+    RETURNS BYTEA
+    LANGUAGE plpgsql IMMUTABLE PARALLEL SAFE AS $$
+DECLARE
+    data_offset NUMERIC := f_abi_uint(args, head_offset);
+    data_length NUMERIC;
+BEGIN
+    IF data_offset IS NULL OR data_offset + 32 > length(args) THEN
+        RETURN NULL;
+    END IF;
+    data_length := f_abi_uint(args, data_offset::BIGINT);
+    IF data_offset + 32 + data_length > length(args) THEN
+        RETURN NULL;
+    END IF;
+    RETURN substring(args FROM data_offset::INT + 33 FOR data_length::INT);
+END $$;
+
+-- The output type, from the selector of the raw data
+CREATE FUNCTION f_output_type(raw_data BYTEA)
+    RETURNS "OutputType"
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT CASE substring(raw_data FROM 1 FOR 4)
+        WHEN '\x237a816f'::BYTEA THEN 'VOUCHER'::"OutputType"
+        WHEN '\xc258d6e5'::BYTEA THEN 'NOTICE'::"OutputType"
+        WHEN '\x10321e8b'::BYTEA THEN 'DELEGATE_CALL_VOUCHER'::"OutputType"
+    END
+$$;
+
+CREATE FUNCTION f_output_destination(raw_data BYTEA)
+    RETURNS BYTEA
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT CASE
+        WHEN f_output_type(raw_data) IN ('VOUCHER', 'DELEGATE_CALL_VOUCHER')
+            AND length(raw_data) >= 36
+        THEN substring(raw_data FROM 17 FOR 20)
+    END
+$$;
+
+CREATE FUNCTION f_output_value(raw_data BYTEA)
+    RETURNS NUMERIC
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT CASE f_output_type(raw_data)
+        WHEN 'VOUCHER' THEN f_abi_uint(substring(raw_data FROM 5), 32)
+    END
+$$;
+
+CREATE FUNCTION f_output_payload(raw_data BYTEA)
+    RETURNS BYTEA
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT CASE f_output_type(raw_data)
+        WHEN 'VOUCHER' THEN f_abi_bytes(substring(raw_data FROM 5), 64)
+        WHEN 'NOTICE' THEN f_abi_bytes(substring(raw_data FROM 5), 0)
+        WHEN 'DELEGATE_CALL_VOUCHER' THEN f_abi_bytes(substring(raw_data FROM 5), 32)
+    END
+$$;
+
+-- Generated columns are computed for the existing outputs too
+ALTER TABLE "output"
+    ADD COLUMN "type" "OutputType" GENERATED ALWAYS AS (f_output_type("raw_data")) STORED,
+    ADD COLUMN "destination" BYTEA GENERATED ALWAYS AS (f_output_destination("raw_data")) STORED,
+    ADD COLUMN "value" NUMERIC(78,0) GENERATED ALWAYS AS (f_output_value("raw_data")) STORED,
+    ADD COLUMN "payload" BYTEA GENERATED ALWAYS AS (f_output_payload("raw_data")) STORED;
+
+CREATE INDEX "output_type_idx" ON "output"("type");
+CREATE INDEX "output_destination_idx" ON "output"("destination");
+
+CREATE OR REPLACE VIEW graphql."outputs" AS
+    SELECT
+        o."index",
+        o."raw_data",
+        o."output_hashes_siblings",
+        o."transaction_hash",
+        i."index" as "input_index",
+        o."type",
+        o."destination",
+        o."value",
+        o."payload"
+    FROM
+        "output" o
+    INNER JOIN
+        "input" i on o."input_id"=i."id";
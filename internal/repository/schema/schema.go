@@ -19,7 +19,9 @@ import (
 //go:embed migrations/*
 var content embed.FS
 
-const ExpectedVersion uint = 2
+const ExpectedVersion uint = 4
+
+var ErrVersionMismatch = errors.New("database schema version mismatch")
 
 type Schema struct {
 	migrate *mig.Migrate
@@ -61,6 +63,27 @@ func (s *Schema) Downgrade() error {
 	return nil
 }
 
+// DowngradeSteps reverts the last n migrations
+func (s *Schema) DowngradeSteps(n uint) error {
+	if n == 0 {
+		return nil
+	}
+	if err := s.migrate.Steps(-int(n)); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Dirty reports whether the last migration failed halfway,
+// in which case the database must be fixed by hand
+func (s *Schema) Dirty() (bool, error) {
+	_, dirty, err := s.migrate.Version()
+	if err != nil && errors.Is(err, migrate.ErrNilVersion) {
+		return false, nil
+	}
+	return dirty, err
+}
+
 func (s *Schema) Close() {
 	source, db := s.migrate.Close()
 	if source != nil {
@@ -78,8 +101,15 @@ func (s *Schema) ValidateVersion() (uint, error) {
 	}
 
 	if version != ExpectedVersion {
-		format := "Database schema version mismatch. Expected %d but it is %d"
-		return 0, fmt.Errorf(format, ExpectedVersion, version)
+		format := "%w. Expected %d but it is %d"
+		return 0, fmt.Errorf(format, ErrVersionMismatch, ExpectedVersion, version)
+	}
+	dirty, err := s.Dirty()
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("Database schema version %d is dirty, a migration failed halfway", version)
 	}
 	return version, nil
 }
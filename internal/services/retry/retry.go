@@ -5,7 +5,10 @@ package retry
 
 import (
 	"log/slog"
+	"sync/atomic"
 	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
 )
 
 // Implements a simple method call retry policy.
@@ -28,11 +31,14 @@ func CallFunctionWithRetryPolicy[
 	for i := uint64(0); i <= maxRetries; i++ {
 
 		if i != 0 {
+			metrics.Retries.WithLabelValues(infoLabel).Inc()
 			slog.Info("Retry Policy: Retrying...", "delay", maxDelay)
 			time.Sleep(maxDelay)
 		}
 
+		start := time.Now()
 		lastValue, lastErr = fn(args)
+		metrics.ObserveRpc(infoLabel, start)
 		if lastErr == nil {
 			return lastValue, nil
 		}
@@ -44,6 +50,45 @@ func CallFunctionWithRetryPolicy[
 			lastErr.Error())
 
 	}
+	metrics.RetriesExhausted.WithLabelValues(infoLabel).Inc()
 	return lastValue, lastErr
 
 }
+
+// Policy holds how many times and how often to retry,
+// so it can be changed while the delegators that retry with it run.
+// It is safe for concurrent use.
+type Policy struct {
+	maxRetries atomic.Uint64
+	maxDelay   atomic.Int64
+}
+
+func NewPolicy(maxRetries uint64, maxDelay time.Duration) *Policy {
+	policy := &Policy{}
+	policy.Set(maxRetries, maxDelay)
+	return policy
+}
+
+// Set changes the policy for the calls that start after it
+func (p *Policy) Set(maxRetries uint64, maxDelay time.Duration) {
+	p.maxRetries.Store(maxRetries)
+	p.maxDelay.Store(int64(maxDelay))
+}
+
+func (p *Policy) MaxRetries() uint64 {
+	return p.maxRetries.Load()
+}
+
+func (p *Policy) MaxDelay() time.Duration {
+	return time.Duration(p.maxDelay.Load())
+}
+
+// CallFunctionWithPolicy calls CallFunctionWithRetryPolicy with the current values of policy
+func CallFunctionWithPolicy[R any, A any](
+	fn func(A) (R, error),
+	args A,
+	policy *Policy,
+	infoLabel string,
+) (R, error) {
+	return CallFunctionWithRetryPolicy(fn, args, policy.MaxRetries(), policy.MaxDelay(), infoLabel)
+}
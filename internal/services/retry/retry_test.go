@@ -8,6 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ZzzzHui/espresso-reader/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -52,11 +55,34 @@ func (s *RetrySuite) TestRetryMaxRetries() {
 		mock.Anything).
 		Return(0, fmt.Errorf("An error"))
 
+	retries := testutil.ToFloat64(metrics.Retries.WithLabelValues("TEST"))
+	exhausted := testutil.ToFloat64(metrics.RetriesExhausted.WithLabelValues("TEST"))
+
 	_, err := CallFunctionWithRetryPolicy(simpleMock.execute, 0, 3, 1*time.Millisecond, "TEST")
 	s.Require().NotNil(err)
 
 	simpleMock.AssertNumberOfCalls(s.T(), "execute", 4)
+	s.Equal(retries+3, testutil.ToFloat64(metrics.Retries.WithLabelValues("TEST")))
+	s.Equal(exhausted+1, testutil.ToFloat64(metrics.RetriesExhausted.WithLabelValues("TEST")))
+
+}
+
+func (s *RetrySuite) TestRetryWithAChangedPolicy() {
+	simpleMock := &SimpleMock{}
+	simpleMock.On(
+		"execute",
+		mock.Anything).
+		Return(0, fmt.Errorf("An error"))
+
+	policy := NewPolicy(3, 1*time.Millisecond)
+	policy.Set(1, 2*time.Millisecond)
+	s.Equal(uint64(1), policy.MaxRetries())
+	s.Equal(2*time.Millisecond, policy.MaxDelay())
 
+	_, err := CallFunctionWithPolicy(simpleMock.execute, 0, policy, "TEST")
+	s.Require().NotNil(err)
+
+	simpleMock.AssertNumberOfCalls(s.T(), "execute", 2)
 }
 
 type SimpleMock struct {
@@ -0,0 +1,107 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+package startup
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// How to make the database follow the config, when the config is the one to keep
+const updateNodeConfigHint = "if the configured values are the right ones, " +
+	"store them with `espresso-reader db node-config update`"
+
+// PreflightError reports what the node found wrong before it started
+type PreflightError struct {
+	Problems []string
+	Hint     string
+}
+
+func (e *PreflightError) Error() string {
+	report := "preflight checks failed: " + strings.Join(e.Problems, "; ")
+	if e.Hint != "" {
+		report += " (" + e.Hint + ")"
+	}
+	return report
+}
+
+// ChainReader is what the preflight checks read from the blockchain
+type ChainReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// CheckChain fails unless the blockchain is the configured one
+// and the InputBox is deployed on it
+func CheckChain(ctx context.Context, chain ChainReader, c config.NodeConfig) error {
+	var problems []string
+
+	chainId, err := chain.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the chain id: %w", err)
+	}
+	if !chainId.IsUint64() || chainId.Uint64() != c.BlockchainID {
+		problems = append(problems, fmt.Sprintf(
+			"CARTESI_BLOCKCHAIN_ID is %d, but CARTESI_BLOCKCHAIN_HTTP_ENDPOINT is on chain %s",
+			c.BlockchainID, chainId))
+	}
+
+	inputBox := common.HexToAddress(c.ContractsInputBoxAddress)
+	code, err := chain.CodeAt(ctx, inputBox, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read the code of the InputBox: %w", err)
+	}
+	if len(code) == 0 {
+		problems = append(problems, fmt.Sprintf(
+			"there is no contract at CARTESI_CONTRACTS_INPUT_BOX_ADDRESS %s on chain %s",
+			inputBox, chainId))
+	}
+
+	if len(problems) > 0 {
+		return &PreflightError{Problems: problems}
+	}
+	return nil
+}
+
+// NewNodePersistentConfig returns the persistent config of the node set up with c
+func NewNodePersistentConfig(c config.NodeConfig) *model.NodePersistentConfig {
+	return &model.NodePersistentConfig{
+		DefaultBlock:            c.EvmReaderDefaultBlock,
+		InputBoxDeploymentBlock: uint64(c.ContractsInputBoxDeploymentBlockNumber),
+		InputBoxAddress:         common.HexToAddress(c.ContractsInputBoxAddress),
+		ChainId:                 c.BlockchainID,
+	}
+}
+
+// CheckNodePersistentConfig fails if the config persisted in the database
+// disagrees with the configured one on the chain the node reads
+func CheckNodePersistentConfig(persisted, configured *model.NodePersistentConfig) error {
+	var problems []string
+	if persisted.ChainId != configured.ChainId {
+		problems = append(problems, fmt.Sprintf(
+			"CARTESI_BLOCKCHAIN_ID is %d, but the database was set up for chain %d",
+			configured.ChainId, persisted.ChainId))
+	}
+	if persisted.InputBoxAddress != configured.InputBoxAddress {
+		problems = append(problems, fmt.Sprintf(
+			"CARTESI_CONTRACTS_INPUT_BOX_ADDRESS is %s, but the database was set up with %s",
+			configured.InputBoxAddress, persisted.InputBoxAddress))
+	}
+	if persisted.InputBoxDeploymentBlock != configured.InputBoxDeploymentBlock {
+		problems = append(problems, fmt.Sprintf(
+			"CARTESI_CONTRACTS_INPUT_BOX_DEPLOYMENT_BLOCK_NUMBER is %d, but the database was set up with %d",
+			configured.InputBoxDeploymentBlock, persisted.InputBoxDeploymentBlock))
+	}
+
+	if len(problems) > 0 {
+		return &PreflightError{Problems: problems, Hint: updateNodeConfigHint}
+	}
+	return nil
+}
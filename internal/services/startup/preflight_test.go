@@ -0,0 +1,90 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package startup
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+	"github.com/ZzzzHui/espresso-reader/internal/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+const preflightInputBox = "0x593E5BCf894D6829Dd26D0810DA7F064406aebB6"
+
+type fakeChain struct {
+	chainId *big.Int
+	code    map[common.Address][]byte
+	err     error
+}
+
+func (c *fakeChain) ChainID(ctx context.Context) (*big.Int, error) {
+	return c.chainId, c.err
+}
+
+func (c *fakeChain) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.code[account], nil
+}
+
+type PreflightSuite struct {
+	suite.Suite
+	chain  *fakeChain
+	config config.NodeConfig
+}
+
+func TestPreflightSuite(t *testing.T) {
+	suite.Run(t, new(PreflightSuite))
+}
+
+func (s *PreflightSuite) SetupTest() {
+	s.chain = &fakeChain{
+		chainId: big.NewInt(31337),
+		code:    map[common.Address][]byte{common.HexToAddress(preflightInputBox): {0x60, 0x80}},
+	}
+	s.config = config.NodeConfig{
+		BlockchainID:                           31337,
+		ContractsInputBoxAddress:               preflightInputBox,
+		ContractsInputBoxDeploymentBlockNumber: 10,
+		EvmReaderDefaultBlock:                  model.DefaultBlockStatusFinalized,
+	}
+}
+
+func (s *PreflightSuite) TestItAcceptsTheConfiguredChain() {
+	s.Nil(CheckChain(context.Background(), s.chain, s.config))
+}
+
+func (s *PreflightSuite) TestItReportsTheWrongChain() {
+	s.chain.chainId = big.NewInt(1)
+	s.chain.code = nil
+	err := CheckChain(context.Background(), s.chain, s.config)
+	var preflight *PreflightError
+	s.Require().ErrorAs(err, &preflight)
+	s.Equal([]string{
+		"CARTESI_BLOCKCHAIN_ID is 31337, but CARTESI_BLOCKCHAIN_HTTP_ENDPOINT is on chain 1",
+		"there is no contract at CARTESI_CONTRACTS_INPUT_BOX_ADDRESS " + preflightInputBox + " on chain 1",
+	}, preflight.Problems)
+
+	s.chain.err = errors.New("connection refused")
+	s.ErrorContains(CheckChain(context.Background(), s.chain, s.config),
+		"failed to read the chain id: connection refused")
+}
+
+func (s *PreflightSuite) TestItComparesThePersistentConfig() {
+	persisted := NewNodePersistentConfig(s.config)
+	s.Nil(CheckNodePersistentConfig(persisted, NewNodePersistentConfig(s.config)))
+
+	s.config.BlockchainID = 11155111
+	s.config.ContractsInputBoxDeploymentBlockNumber = 20
+	err := CheckNodePersistentConfig(persisted, NewNodePersistentConfig(s.config))
+	s.EqualError(err, "preflight checks failed: "+
+		"CARTESI_BLOCKCHAIN_ID is 11155111, but the database was set up for chain 31337; "+
+		"CARTESI_CONTRACTS_INPUT_BOX_DEPLOYMENT_BLOCK_NUMBER is 20, but the database was set up with 10 "+
+		"(if the configured values are the right ones, "+
+		"store them with `espresso-reader db node-config update`)")
+}
@@ -6,33 +6,90 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 
 	"github.com/ZzzzHui/espresso-reader/internal/config"
 	"github.com/ZzzzHui/espresso-reader/internal/model"
 	"github.com/ZzzzHui/espresso-reader/internal/repository"
+	"github.com/ZzzzHui/espresso-reader/internal/repository/schema"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v5"
 	"github.com/lmittmann/tint"
 	"github.com/mattn/go-isatty"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// Configure the node logs
-func ConfigLogs(logLevel slog.Level, logPrettyEnabled bool) {
-	opts := &tint.Options{
-		Level:      logLevel,
-		AddSource:  logLevel == slog.LevelDebug,
-		NoColor:    !logPrettyEnabled || !isatty.IsTerminal(os.Stdout.Fd()),
-		TimeFormat: "2006-01-02T15:04:05.000", // RFC3339 with milliseconds and without timezone
+// Formats of the node logs
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Configure the node logs.
+// The returned level can be changed while the node runs,
+// and the returned function closes the log file, if any.
+func ConfigLogs(c config.NodeConfig) (*slog.LevelVar, func() error, error) {
+	level := new(slog.LevelVar)
+	level.Set(c.LogLevel)
+
+	var out io.Writer = os.Stdout
+	closeLogs := func() error { return nil }
+	isTerminal := isatty.IsTerminal(os.Stdout.Fd())
+	if c.LogFile != "" {
+		file := &lumberjack.Logger{
+			Filename:   c.LogFile,
+			MaxSize:    int(c.LogFileMaxSize),
+			MaxBackups: int(c.LogFileMaxBackups),
+		}
+		out, closeLogs, isTerminal = file, file.Close, false
+	}
+
+	var handler slog.Handler
+	switch c.LogFormat {
+	case LogFormatText:
+		handler = tint.NewHandler(out, &tint.Options{
+			Level:      level,
+			AddSource:  c.LogLevel == slog.LevelDebug,
+			NoColor:    !c.LogPrettyEnabled || !isTerminal,
+			TimeFormat: "2006-01-02T15:04:05.000", // RFC3339 with milliseconds and without timezone
+		})
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(out, &slog.HandlerOptions{
+			Level:     level,
+			AddSource: c.LogLevel == slog.LevelDebug,
+		})
+	default:
+		return nil, nil, fmt.Errorf("invalid log format '%s', expected %s or %s",
+			c.LogFormat, LogFormatText, LogFormatJSON)
+	}
+	slog.SetDefault(slog.New(handler))
+	return level, closeLogs, nil
+}
+
+// Applies the pending database migrations
+func MigrateDatabase(postgresEndpoint string) error {
+	schema, err := schema.New(postgresEndpoint)
+	if err != nil {
+		return err
 	}
-	handler := tint.NewHandler(os.Stdout, opts)
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
+	defer schema.Close()
+
+	err = schema.Upgrade()
+	if err != nil {
+		return err
+	}
+	version, err := schema.ValidateVersion()
+	if err != nil {
+		return err
+	}
+	slog.Info("Database schema is up to date", "version", version)
+	return nil
 }
 
-// Handles Persistent Config
+// Handles Persistent Config.
+// It fails if the config persisted in the database disagrees with the configured one.
 func SetupNodePersistentConfig(
 	ctx context.Context,
 	database *repository.Database,
@@ -49,12 +106,7 @@ func SetupNodePersistentConfig(
 	}
 
 	if nodePersistentConfig == nil {
-		nodePersistentConfig = &model.NodePersistentConfig{
-			DefaultBlock:            config.EvmReaderDefaultBlock,
-			InputBoxDeploymentBlock: uint64(config.ContractsInputBoxDeploymentBlockNumber),
-			InputBoxAddress:         common.HexToAddress(config.ContractsInputBoxAddress),
-			ChainId:                 config.BlockchainID,
-		}
+		nodePersistentConfig = NewNodePersistentConfig(config)
 		slog.Info(
 			"No persistent config found at the database. Setting it up",
 			"persistent config",
@@ -66,6 +118,10 @@ func SetupNodePersistentConfig(
 			return nil, fmt.Errorf("Couldn't insert database config. Error : %v", err)
 		}
 	} else {
+		err = CheckNodePersistentConfig(nodePersistentConfig, NewNodePersistentConfig(config))
+		if err != nil {
+			return nil, err
+		}
 		slog.Info(
 			"Node was already configured. Using previous persistent config",
 			"persistent config",
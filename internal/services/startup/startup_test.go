@@ -0,0 +1,95 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package startup
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/internal/config"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LogsSuite struct {
+	suite.Suite
+	logger *slog.Logger
+}
+
+func TestLogsSuite(t *testing.T) {
+	suite.Run(t, new(LogsSuite))
+}
+
+func (s *LogsSuite) SetupTest() {
+	s.logger = slog.Default()
+}
+
+func (s *LogsSuite) TearDownTest() {
+	slog.SetDefault(s.logger)
+}
+
+func (s *LogsSuite) readRecords(path string) []map[string]any {
+	file, err := os.Open(path)
+	s.Require().Nil(err)
+	defer file.Close()
+	var records []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]any
+		s.Require().Nil(json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	return records
+}
+
+func (s *LogsSuite) TestItWritesJSONToTheLogFile() {
+	path := filepath.Join(s.T().TempDir(), "reader.log")
+	_, closeLogs, err := ConfigLogs(config.NodeConfig{
+		LogLevel:       slog.LevelInfo,
+		LogFormat:      LogFormatJSON,
+		LogFile:        path,
+		LogFileMaxSize: 1,
+	})
+	s.Require().Nil(err)
+	slog.Info("Espresso input", "app", "0x2E663fe9aE92275242406A185AA4fC8174339D3E",
+		"espresso_height", 1500, "l1_block", 90, "tx_id", "0x01")
+	slog.Debug("not logged")
+	s.Require().Nil(closeLogs())
+
+	records := s.readRecords(path)
+	s.Require().Len(records, 1)
+	s.Equal("INFO", records[0]["level"])
+	s.Equal("Espresso input", records[0]["msg"])
+	s.Equal("0x2E663fe9aE92275242406A185AA4fC8174339D3E", records[0]["app"])
+	s.Equal(1500.0, records[0]["espresso_height"])
+	s.Equal(90.0, records[0]["l1_block"])
+	s.Equal("0x01", records[0]["tx_id"])
+}
+
+func (s *LogsSuite) TestItChangesTheLevelAtRuntime() {
+	path := filepath.Join(s.T().TempDir(), "reader.log")
+	level, closeLogs, err := ConfigLogs(config.NodeConfig{
+		LogLevel:  slog.LevelWarn,
+		LogFormat: LogFormatJSON,
+		LogFile:   path,
+	})
+	s.Require().Nil(err)
+	slog.Info("before")
+	level.Set(slog.LevelDebug)
+	slog.Debug("after")
+	s.Require().Nil(closeLogs())
+
+	records := s.readRecords(path)
+	s.Require().Len(records, 1)
+	s.Equal("after", records[0]["msg"])
+}
+
+func (s *LogsSuite) TestItRejectsUnknownFormats() {
+	_, _, err := ConfigLogs(config.NodeConfig{LogFormat: "xml"})
+	s.ErrorContains(err, "invalid log format 'xml'")
+}
@@ -0,0 +1,97 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package tracing
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// How long the span of a submission is kept for the ingestion of its transaction
+const submissionTtl = time.Hour
+
+// How many submissions are kept at most. The oldest ones are forgotten first.
+const maxSubmissions = 100_000
+
+type submission struct {
+	spanContext trace.SpanContext
+	time        time.Time
+}
+
+// recorded is a transaction, in the order it was recorded
+type recorded struct {
+	txId string
+	time time.Time
+}
+
+// Submissions remembers the spans of the transactions submitted through the service,
+// so the span that ingests each of them links back to where it came from
+type Submissions struct {
+	mutex       sync.Mutex
+	submissions map[string]submission
+	// the recorded transactions, oldest first, so expiring them doesn't scan the map
+	order *list.List
+	limit int
+	now   func() time.Time
+}
+
+// DefaultSubmissions links the submissions of the service to the ingestions of the reader
+var DefaultSubmissions = NewSubmissions()
+
+func NewSubmissions() *Submissions {
+	return &Submissions{
+		submissions: map[string]submission{},
+		order:       list.New(),
+		limit:       maxSubmissions,
+		now:         time.Now,
+	}
+}
+
+// Record remembers the span in ctx as the one that submitted the transaction txId
+func (s *Submissions) Record(ctx context.Context, txId string) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.now()
+	s.expire(now)
+	id := strings.ToLower(txId)
+	s.submissions[id] = submission{spanContext: spanContext, time: now}
+	s.order.PushBack(recorded{txId: id, time: now})
+}
+
+// expire forgets the submissions older than submissionTtl, and the oldest ones
+// while there are too many. The caller must hold the mutex.
+func (s *Submissions) expire(now time.Time) {
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		oldest := front.Value.(recorded)
+		if s.order.Len() < s.limit && now.Sub(oldest.time) <= submissionTtl {
+			return
+		}
+		s.order.Remove(front)
+		// unless it was linked, or recorded again since
+		if submission, ok := s.submissions[oldest.txId]; ok && submission.time.Equal(oldest.time) {
+			delete(s.submissions, oldest.txId)
+		}
+	}
+}
+
+// Link links span to the span that submitted the transaction txId, if it was submitted
+// through this service, and forgets about the submission
+func (s *Submissions) Link(span trace.Span, txId string) {
+	s.mutex.Lock()
+	submission, ok := s.submissions[strings.ToLower(txId)]
+	delete(s.submissions, strings.ToLower(txId))
+	s.mutex.Unlock()
+	if ok {
+		span.AddLink(trace.Link{SpanContext: submission.spanContext})
+	}
+}
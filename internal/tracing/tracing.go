@@ -0,0 +1,106 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package tracing holds the OpenTelemetry traces of the Espresso and EVM readers,
+// from fetching the Espresso blocks to storing the inputs.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName = "espresso-reader"
+	tracerName  = "github.com/ZzzzHui/espresso-reader"
+)
+
+// Exporters the traces can be sent to
+const (
+	ExporterNone   = "none"
+	ExporterOtlp   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Attributes of the spans
+const (
+	AttrApp           = attribute.Key("espresso_reader.app")
+	AttrTxId          = attribute.Key("espresso_reader.tx_id")
+	AttrEspressoBlock = attribute.Key("espresso_reader.espresso_block")
+	AttrL1Block       = attribute.Key("espresso_reader.l1_block")
+	AttrFromBlock     = attribute.Key("espresso_reader.from_block")
+	AttrToBlock       = attribute.Key("espresso_reader.to_block")
+	AttrInputs        = attribute.Key("espresso_reader.inputs")
+)
+
+// Setup installs the global tracer provider, which sends the spans to exporter.
+// The otlp exporter is configured by the standard OTEL_EXPORTER_OTLP_* variables,
+// and the stdout exporter writes to stdout.
+// The returned function flushes the pending spans and must be called on exit.
+func Setup(ctx context.Context, exporter string, stdout io.Writer) (func(context.Context) error, error) {
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOtlp:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(stdout))
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter '%s', expected one of %s, %s or %s",
+			exporter, ExporterNone, ExporterOtlp, ExporterStdout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s trace exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name, child of the span in ctx, if any
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartRequest starts the server span of r, child of the span the client sent
+// in the trace context headers, if any
+func StartRequest(r *http.Request, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it as failed if err isn't nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
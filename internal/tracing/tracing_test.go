@@ -0,0 +1,149 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const testTxId = "0x4f7a2b1d32b9f7bd69e2bd8c2cbf8df1f23db3a4e6fcb24d63af4b6e8ba30a1c"
+
+type TracingSuite struct {
+	suite.Suite
+	recorder *tracetest.SpanRecorder
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingSuite))
+}
+
+func (s *TracingSuite) SetupTest() {
+	s.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)))
+}
+
+func (s *TracingSuite) TearDownTest() {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func (s *TracingSuite) TestItRejectsUnknownExporters() {
+	_, err := Setup(context.Background(), "jaeger", nil)
+	s.ErrorContains(err, "invalid tracing exporter 'jaeger'")
+}
+
+func (s *TracingSuite) TestItExportsToStdout() {
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), ExporterStdout, &out)
+	s.Require().Nil(err)
+	_, span := Start(context.Background(), "Espresso::GetHeader", AttrEspressoBlock.Int64(7))
+	span.End()
+	s.Require().Nil(shutdown(context.Background()))
+	s.Contains(out.String(), `"Name":"Espresso::GetHeader"`)
+	s.Contains(out.String(), "espresso-reader")
+}
+
+func (s *TracingSuite) TestItMarksFailedSpans() {
+	_, span := Start(context.Background(), "ExtractSigAndData")
+	End(span, errors.New("decode base64"))
+	_, span = Start(context.Background(), "ExtractSigAndData")
+	End(span, nil)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 2)
+	s.Equal(codes.Error, spans[0].Status().Code)
+	s.Equal("decode base64", spans[0].Status().Description)
+	s.Equal(codes.Unset, spans[1].Status().Code)
+}
+
+func (s *TracingSuite) TestItLinksIngestionsToSubmissions() {
+	submissions := NewSubmissions()
+	ctx, submit := Start(context.Background(), "Service::SubmitTransaction")
+	submissions.Record(ctx, testTxId)
+	submit.End()
+
+	_, ingest := Start(context.Background(), "EspressoReader::ReadTransaction")
+	submissions.Link(ingest, "0x4F7A2B1D32B9F7BD69E2BD8C2CBF8DF1F23DB3A4E6FCB24D63AF4B6E8BA30A1C")
+	ingest.End()
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 2)
+	s.Require().Len(spans[1].Links(), 1)
+	s.Equal(spans[0].SpanContext(), spans[1].Links()[0].SpanContext)
+	s.NotEqual(spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+
+	// each submission is linked once
+	_, again := Start(context.Background(), "EspressoReader::ReadTransaction")
+	submissions.Link(again, testTxId)
+	again.End()
+	s.Empty(s.recorder.Ended()[2].Links())
+}
+
+func (s *TracingSuite) TestItForgetsOldSubmissions() {
+	submissions := NewSubmissions()
+	now := time.Now()
+	submissions.now = func() time.Time { return now }
+	ctx, submit := Start(context.Background(), "Service::SubmitTransaction")
+	submissions.Record(ctx, testTxId)
+	submit.End()
+
+	// the next submission prunes the expired ones
+	now = now.Add(submissionTtl + time.Second)
+	submissions.Record(ctx, "0x01")
+	s.Len(submissions.submissions, 1)
+	s.Equal(1, submissions.order.Len())
+}
+
+func (s *TracingSuite) TestItKeepsALimitedNumberOfSubmissions() {
+	submissions := NewSubmissions()
+	submissions.limit = 2
+	ctx, submit := Start(context.Background(), "Service::SubmitTransaction")
+	for _, txId := range []string{"0x01", "0x02", "0x03"} {
+		submissions.Record(ctx, txId)
+	}
+	submit.End()
+
+	s.Len(submissions.submissions, 2)
+	s.NotContains(submissions.submissions, "0x01")
+	s.Contains(submissions.submissions, "0x03")
+}
+
+func (s *TracingSuite) TestItKeepsSubmissionsRecordedAgain() {
+	submissions := NewSubmissions()
+	now := time.Now()
+	submissions.now = func() time.Time { return now }
+	ctx, submit := Start(context.Background(), "Service::SubmitTransaction")
+	submissions.Record(ctx, testTxId)
+	now = now.Add(submissionTtl / 2)
+	submissions.Record(ctx, testTxId)
+	submit.End()
+
+	// the first record of the transaction expired, but not the second
+	now = now.Add(submissionTtl/2 + time.Second)
+	submissions.Record(ctx, "0x01")
+	s.Contains(submissions.submissions, testTxId)
+}
+
+func (s *TracingSuite) TestItIgnoresSubmissionsWithoutSpans() {
+	submissions := NewSubmissions()
+	submissions.Record(context.Background(), testTxId)
+	s.Empty(submissions.submissions)
+
+	_, ingest := Start(context.Background(), "EspressoReader::ReadTransaction")
+	submissions.Link(ingest, testTxId)
+	ingest.End()
+	s.Empty(s.recorder.Ended()[0].Links())
+	s.Equal(trace.SpanKindInternal, s.recorder.Ended()[0].SpanKind())
+}
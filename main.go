@@ -3,15 +3,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/ZzzzHui/espresso-reader/internal/cli/app"
+	configcli "github.com/ZzzzHui/espresso-reader/internal/cli/config"
+	"github.com/ZzzzHui/espresso-reader/internal/cli/db"
+	"github.com/ZzzzHui/espresso-reader/internal/cli/input"
+	"github.com/ZzzzHui/espresso-reader/internal/cli/send"
+	"github.com/ZzzzHui/espresso-reader/internal/cli/status"
 	"github.com/ZzzzHui/espresso-reader/internal/config"
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader"
 	"github.com/ZzzzHui/espresso-reader/internal/espressoreader/service"
 	"github.com/ZzzzHui/espresso-reader/internal/repository"
+	"github.com/ZzzzHui/espresso-reader/internal/repository/schema"
 	"github.com/ZzzzHui/espresso-reader/internal/services/startup"
+	"github.com/ZzzzHui/espresso-reader/internal/tracing"
 
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/spf13/cobra"
 )
 
@@ -32,35 +46,106 @@ var Cmd = &cobra.Command{
 	Run:   run,
 }
 
+var (
+	autoMigrate bool
+	configFile  string
+)
+
 func run(cmd *cobra.Command, args []string) {
 	startTime := time.Now()
 
 	ctx := cmd.Context()
 
-	c := config.FromEnv()
+	if configFile != "" {
+		if err := config.LoadFile(configFile); err != nil {
+			slog.Error("Espresso Reader couldn't load the config file", "error", err)
+			os.Exit(1)
+		}
+	}
+	c, err := config.Load()
+	if err != nil {
+		slog.Error("Espresso Reader configuration is invalid", "error", err)
+		os.Exit(1)
+	}
 
 	// setup log
-	startup.ConfigLogs(c.LogLevel, c.LogPrettyEnabled)
+	logLevel, closeLogs, err := startup.ConfigLogs(c)
+	if err != nil {
+		slog.Error("Espresso Reader couldn't configure the logs", "error", err)
+		os.Exit(1)
+	}
+	defer closeLogs()
+	// remember the errors of the readers for the status endpoint
+	errorRecorder := service.NewErrorRecorder(slog.Default().Handler())
+	slog.SetDefault(slog.New(errorRecorder))
 
 	slog.Info("Starting the Cartesi Rollups Node Espresso Reader", "config", c)
 
+	shutdownTracing, err := tracing.Setup(ctx, c.EspressoTracingExporter, os.Stdout)
+	if err != nil {
+		slog.Error("Espresso Reader couldn't set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Espresso Reader couldn't flush the traces", "error", err)
+		}
+	}()
+
+	if c.PostgresAutoMigrate || autoMigrate {
+		err := startup.MigrateDatabase(c.PostgresEndpoint.Value)
+		if err != nil {
+			slog.Error("Espresso Reader couldn't migrate the database", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	database, err := repository.Connect(ctx, c.PostgresEndpoint.Value)
 	if err != nil {
+		if errors.Is(err, schema.ErrVersionMismatch) {
+			slog.Error("Espresso Reader refuses to run on this database schema. "+
+				"Run the `db migrate up` command, or set CARTESI_POSTGRES_AUTO_MIGRATE",
+				"error", err)
+			os.Exit(1)
+		}
 		slog.Error("EVM Reader couldn't connect to the database", "error", err)
 		os.Exit(1)
 	}
 	defer database.Close()
 
+	// check the chain before persisting its config or reading from it
+	if err := checkChain(ctx, c); err != nil {
+		slog.Error("Espresso Reader refuses to run on this chain", "error", err)
+		os.Exit(1)
+	}
+
 	_, err = startup.SetupNodePersistentConfig(ctx, database, c)
 	if err != nil {
-		slog.Error("EVM Reader couldn't connect to the database", "error", err)
+		slog.Error("Espresso Reader couldn't set up the persistent config", "error", err)
+		os.Exit(1)
+	}
+
+	apiKeys, err := service.LoadApiKeys(c.EspressoServiceApiKeys.Value, c.EspressoServiceApiKeysFile)
+	if err != nil {
+		slog.Error("Espresso Reader couldn't load the service API keys", "error", err)
+		os.Exit(1)
+	}
+	anonymousScopes, err := service.ParseScopes(c.EspressoServiceAnonymousScopes)
+	if err != nil {
+		slog.Error("Espresso Reader couldn't parse the service anonymous scopes", "error", err)
+		os.Exit(1)
+	}
+	trustedProxies, err := service.ParseTrustedProxies(c.EspressoServiceTrustedProxies)
+	if err != nil {
+		slog.Error("Espresso Reader couldn't parse the service trusted proxies", "error", err)
 		os.Exit(1)
 	}
 
 	// create Espresso Reader Service
-	service := service.NewEspressoReaderService(
-		c.BlockchainHttpEndpoint.Value,
+	readerService, err := service.NewEspressoReaderService(
 		c.BlockchainHttpEndpoint.Value,
+		c.BlockchainWsEndpoint.Value,
+		c.BlockchainPollingInterval,
 		database,
 		c.EspressoBaseUrl,
 		c.EspressoStartingBlock,
@@ -69,8 +154,37 @@ func run(cmd *cobra.Command, args []string) {
 		c.EvmReaderRetryPolicyMaxDelay,
 		c.BlockchainID,
 		uint64(c.ContractsInputBoxDeploymentBlockNumber),
-		c.EspressoServiceEndpoint,
+		service.HttpServerConfig{
+			Endpoint:           c.EspressoServiceEndpoint,
+			ReadTimeout:        c.EspressoServiceReadTimeout,
+			WriteTimeout:       c.EspressoServiceWriteTimeout,
+			ShutdownTimeout:    c.EspressoServiceShutdownTimeout,
+			TlsCertFile:        c.EspressoServiceTlsCertFile,
+			TlsKeyFile:         c.EspressoServiceTlsKeyFile,
+			CorsAllowedOrigins: c.EspressoServiceCorsAllowedOrigins,
+			TrustedProxies:     trustedProxies,
+			MaxRequestSize:     c.EspressoServiceMaxRequestSize,
+			Auth: service.AuthConfig{
+				Keys:            apiKeys,
+				AnonymousScopes: anonymousScopes,
+				MaxClockSkew:    c.EspressoServiceAuthMaxClockSkew,
+			},
+		},
+		c.EspressoNoncePendingTimeout,
+		c.EspressoMaxPayloadSize,
+		rateLimitConfig(c),
+		espressoreader.IngestionLimits{
+			MaxInputsPerBlock:          c.EspressoMaxInputsPerBlock,
+			MaxInputsPerSenderPerBlock: c.EspressoMaxInputsPerSenderPerBlock,
+		},
+		c.EspressoReadyMaxLag,
+		logLevel,
+		errorRecorder,
 	)
+	if err != nil {
+		slog.Error("Espresso Reader couldn't create the service", "error", err)
+		os.Exit(1)
+	}
 
 	// logs startup time
 	ready := make(chan struct{}, 1)
@@ -83,15 +197,78 @@ func run(cmd *cobra.Command, args []string) {
 		}
 	}()
 
+	// reload the reloadable settings on SIGHUP, or when the config file changes
+	reloader, err := config.NewReloader(configFile, func(c config.NodeConfig) {
+		readerService.Apply(service.Settings{
+			LogLevel:    c.LogLevel,
+			MaxRetries:  c.EvmReaderRetryPolicyMaxRetries,
+			MaxDelay:    c.EvmReaderRetryPolicyMaxDelay,
+			RateLimits:  rateLimitConfig(c),
+			ReadyMaxLag: c.EspressoReadyMaxLag,
+		})
+	})
+	if err != nil {
+		slog.Error("Espresso Reader couldn't set up the config reload", "error", err)
+		os.Exit(1)
+	}
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+	go func() {
+		if err := reloader.Run(ctx, hangups); err != nil {
+			slog.Error("Espresso Reader couldn't watch the config file", "error", err)
+		}
+	}()
+
 	// start service
-	if err := service.Start(ctx, ready); err != nil {
+	if err := readerService.Start(ctx, ready); err != nil && ctx.Err() == nil {
 		slog.Error("Espresso Reader exited with an error", "error", err)
 		os.Exit(1)
 	}
+	slog.Info("Espresso Reader stopped")
+}
+
+func checkChain(ctx context.Context, c config.NodeConfig) error {
+	client, err := ethclient.DialContext(ctx, c.BlockchainHttpEndpoint.Value)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return startup.CheckChain(ctx, client, c)
+}
+
+func rateLimitConfig(c config.NodeConfig) service.RateLimitConfig {
+	return service.RateLimitConfig{
+		PerIp: service.RateLimit{
+			PerMinute: c.EspressoSubmitRateLimitPerIp,
+			Burst:     c.EspressoSubmitBurstPerIp,
+		},
+		PerSender: service.RateLimit{
+			PerMinute: c.EspressoSubmitRateLimitPerSender,
+			Burst:     c.EspressoSubmitBurstPerSender,
+		},
+		PerApp: service.RateLimit{
+			PerMinute: c.EspressoSubmitRateLimitPerApp,
+			Burst:     c.EspressoSubmitBurstPerApp,
+		},
+	}
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&autoMigrate, "auto-migrate", false,
+		"Apply the pending database migrations on start, as with CARTESI_POSTGRES_AUTO_MIGRATE")
+	Cmd.Flags().StringVar(&configFile, "config", "",
+		"Path to a TOML or YAML config file, overridden by the environment and the flags")
+	config.AddFlags(Cmd.Flags())
+
+	Cmd.AddCommand(app.Cmd, configcli.Cmd, db.Cmd, input.Cmd, send.Cmd, status.Cmd)
 }
 
 func main() {
-	err := Cmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := Cmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
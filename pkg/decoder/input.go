@@ -0,0 +1,95 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package decoder decodes the inputs the applications receive, and the outputs they emit,
+// as stored in the database.
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ZzzzHui/espresso-reader/pkg/contracts/inputs"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// EvmAdvance is the metadata and payload of an input, as encoded in input.raw_data
+type EvmAdvance struct {
+	ChainId        uint64         `json:"chain_id"`
+	AppContract    common.Address `json:"app_contract"`
+	MsgSender      common.Address `json:"msg_sender"`
+	BlockNumber    uint64         `json:"block_number"`
+	BlockTimestamp uint64         `json:"block_timestamp"`
+	PrevRandao     *hexutil.Big   `json:"prev_randao"`
+	Index          uint64         `json:"index"`
+	Payload        hexutil.Bytes  `json:"payload"`
+}
+
+const evmAdvanceMethod = "EvmAdvance"
+
+func inputsAbi() (*abi.ABI, error) {
+	return inputs.InputsMetaData.GetAbi()
+}
+
+// DecodeEvmAdvance decodes the ABI-encoded EvmAdvance calldata of an input
+func DecodeEvmAdvance(rawData []byte) (*EvmAdvance, error) {
+	parsedAbi, err := inputsAbi()
+	if err != nil {
+		return nil, err
+	}
+	method := parsedAbi.Methods[evmAdvanceMethod]
+	if len(rawData) < 4 || !bytes.Equal(rawData[:4], method.ID) {
+		return nil, fmt.Errorf("raw data is not an %s call", evmAdvanceMethod)
+	}
+	values, err := method.Inputs.Unpack(rawData[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", evmAdvanceMethod, err)
+	}
+
+	uint64s := make([]uint64, 0, 4)
+	for _, i := range []int{0, 3, 4, 6} {
+		value := values[i].(*big.Int)
+		if !value.IsUint64() {
+			return nil, fmt.Errorf("%s argument %s overflows uint64: %s",
+				evmAdvanceMethod, method.Inputs[i].Name, value)
+		}
+		uint64s = append(uint64s, value.Uint64())
+	}
+
+	return &EvmAdvance{
+		ChainId:        uint64s[0],
+		AppContract:    values[1].(common.Address),
+		MsgSender:      values[2].(common.Address),
+		BlockNumber:    uint64s[1],
+		BlockTimestamp: uint64s[2],
+		PrevRandao:     (*hexutil.Big)(values[5].(*big.Int)),
+		Index:          uint64s[3],
+		Payload:        values[7].([]byte),
+	}, nil
+}
+
+// Encode returns the ABI-encoded EvmAdvance calldata of the input
+func (a *EvmAdvance) Encode() ([]byte, error) {
+	parsedAbi, err := inputsAbi()
+	if err != nil {
+		return nil, err
+	}
+	prevRandao := new(big.Int)
+	if a.PrevRandao != nil {
+		prevRandao = a.PrevRandao.ToInt()
+	}
+	return parsedAbi.Pack(evmAdvanceMethod,
+		new(big.Int).SetUint64(a.ChainId),
+		a.AppContract,
+		a.MsgSender,
+		new(big.Int).SetUint64(a.BlockNumber),
+		new(big.Int).SetUint64(a.BlockTimestamp),
+		prevRandao,
+		new(big.Int).SetUint64(a.Index),
+		[]byte(a.Payload),
+	)
+}
@@ -0,0 +1,87 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package decoder
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type InputSuite struct {
+	suite.Suite
+}
+
+func TestInputSuite(t *testing.T) {
+	suite.Run(t, new(InputSuite))
+}
+
+func newTestEvmAdvance() *EvmAdvance {
+	return &EvmAdvance{
+		ChainId:        11155111,
+		AppContract:    common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E"),
+		MsgSender:      common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		BlockNumber:    6000000,
+		BlockTimestamp: 1718000000,
+		PrevRandao:     (*hexutil.Big)(new(big.Int).Lsh(big.NewInt(1), 255)),
+		Index:          7,
+		Payload:        common.FromHex("0xdeadbeef"),
+	}
+}
+
+func (s *InputSuite) TestItDecodesWhatItEncodes() {
+	input := newTestEvmAdvance()
+	rawData, err := input.Encode()
+	s.Require().Nil(err)
+
+	decoded, err := DecodeEvmAdvance(rawData)
+	s.Require().Nil(err)
+	s.Equal(input, decoded)
+}
+
+func (s *InputSuite) TestItDecodesInputsEncodedByTheReaders() {
+	// the readers encode inputs with the unnamed arguments of internal/evmreader/abi.json
+	abiData, err := os.ReadFile("../../internal/evmreader/abi.json")
+	s.Require().Nil(err)
+	ioAbi, err := abi.JSON(strings.NewReader(string(abiData)))
+	s.Require().Nil(err)
+
+	input := newTestEvmAdvance()
+	rawData, err := ioAbi.Pack("EvmAdvance",
+		big.NewInt(int64(input.ChainId)), input.AppContract, input.MsgSender,
+		big.NewInt(int64(input.BlockNumber)), big.NewInt(int64(input.BlockTimestamp)),
+		input.PrevRandao.ToInt(), big.NewInt(int64(input.Index)), []byte(input.Payload))
+	s.Require().Nil(err)
+
+	decoded, err := DecodeEvmAdvance(rawData)
+	s.Require().Nil(err)
+	s.Equal(input, decoded)
+}
+
+func (s *InputSuite) TestItRejectsOtherData() {
+	for _, rawData := range []string{"0x", "0xdeadbeef", "0x415bf363", "0x415bf36300"} {
+		_, err := DecodeEvmAdvance(common.FromHex(rawData))
+		s.Error(err, rawData)
+	}
+}
+
+func (s *InputSuite) TestItRejectsValuesThatOverflow() {
+	parsedAbi, err := inputsAbi()
+	s.Require().Nil(err)
+	input := newTestEvmAdvance()
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), 64)
+	rawData, err := parsedAbi.Pack("EvmAdvance",
+		tooLarge, input.AppContract, input.MsgSender, big.NewInt(1), big.NewInt(1),
+		big.NewInt(1), big.NewInt(1), []byte{})
+	s.Require().Nil(err)
+
+	_, err = DecodeEvmAdvance(rawData)
+	s.ErrorContains(err, "chainId overflows uint64")
+}
@@ -0,0 +1,80 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ZzzzHui/espresso-reader/pkg/contracts/outputs"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// OutputType is the kind of an output, as in the type column of the output table
+type OutputType string
+
+const (
+	OutputTypeVoucher             OutputType = "VOUCHER"
+	OutputTypeNotice              OutputType = "NOTICE"
+	OutputTypeDelegateCallVoucher OutputType = "DELEGATE_CALL_VOUCHER"
+)
+
+// DecodedOutput is an output, as encoded in output.raw_data, split in its fields.
+// Destination is nil for notices and Value is nil unless the output is a voucher.
+//
+// The database splits the outputs the same way, in the f_output_* functions of the
+// 000004_decode_outputs migration, which the tests of this package check against.
+type DecodedOutput struct {
+	Type        OutputType      `json:"type"`
+	Destination *common.Address `json:"destination,omitempty"`
+	Value       *hexutil.Big    `json:"value,omitempty"`
+	Payload     hexutil.Bytes   `json:"payload"`
+}
+
+// Methods of the outputs ABI, by output type
+var outputMethods = map[OutputType]string{
+	OutputTypeVoucher:             "Voucher",
+	OutputTypeNotice:              "Notice",
+	OutputTypeDelegateCallVoucher: "DelegateCallVoucher",
+}
+
+// DecodeOutput classifies the ABI-encoded calldata of an output and decodes its fields
+func DecodeOutput(rawData []byte) (*DecodedOutput, error) {
+	parsedAbi, err := outputs.OutputsMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	if len(rawData) < 4 {
+		return nil, fmt.Errorf("raw data is too short to be an output")
+	}
+	for outputType, name := range outputMethods {
+		method := parsedAbi.Methods[name]
+		if !bytes.Equal(rawData[:4], method.ID) {
+			continue
+		}
+		values, err := method.Inputs.Unpack(rawData[4:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack %s: %w", name, err)
+		}
+		output := &DecodedOutput{Type: outputType}
+		switch outputType {
+		case OutputTypeVoucher:
+			destination := values[0].(common.Address)
+			output.Destination = &destination
+			output.Value = (*hexutil.Big)(values[1].(*big.Int))
+			output.Payload = values[2].([]byte)
+		case OutputTypeDelegateCallVoucher:
+			destination := values[0].(common.Address)
+			output.Destination = &destination
+			output.Payload = values[1].([]byte)
+		case OutputTypeNotice:
+			output.Payload = values[0].([]byte)
+		}
+		return output, nil
+	}
+	return nil, fmt.Errorf("raw data is not a Voucher, Notice or DelegateCallVoucher call")
+}
@@ -0,0 +1,106 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package decoder
+
+import (
+	"math/big"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/pkg/contracts/outputs"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputSuite struct {
+	suite.Suite
+}
+
+func TestOutputSuite(t *testing.T) {
+	suite.Run(t, new(OutputSuite))
+}
+
+var (
+	testDestination = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	testPayload     = common.FromHex("0xdeadbeef")
+)
+
+func (s *OutputSuite) pack(method string, args ...any) []byte {
+	parsedAbi, err := outputs.OutputsMetaData.GetAbi()
+	s.Require().Nil(err)
+	rawData, err := parsedAbi.Pack(method, args...)
+	s.Require().Nil(err)
+	return rawData
+}
+
+func (s *OutputSuite) TestItDecodesVouchers() {
+	value := new(big.Int).Lsh(big.NewInt(1), 200)
+	output, err := DecodeOutput(s.pack("Voucher", testDestination, value, testPayload))
+	s.Require().Nil(err)
+	s.Equal(&DecodedOutput{
+		Type:        OutputTypeVoucher,
+		Destination: &testDestination,
+		Value:       (*hexutil.Big)(value),
+		Payload:     testPayload,
+	}, output)
+}
+
+func (s *OutputSuite) TestItDecodesNotices() {
+	output, err := DecodeOutput(s.pack("Notice", testPayload))
+	s.Require().Nil(err)
+	s.Equal(&DecodedOutput{Type: OutputTypeNotice, Payload: testPayload}, output)
+}
+
+func (s *OutputSuite) TestItDecodesDelegateCallVouchers() {
+	output, err := DecodeOutput(s.pack("DelegateCallVoucher", testDestination, testPayload))
+	s.Require().Nil(err)
+	s.Equal(&DecodedOutput{
+		Type:        OutputTypeDelegateCallVoucher,
+		Destination: &testDestination,
+		Payload:     testPayload,
+	}, output)
+}
+
+func (s *OutputSuite) TestItFailsOnUnknownSelectors() {
+	_, err := DecodeOutput(common.FromHex("0x12345678"))
+	s.ErrorContains(err, "is not a Voucher, Notice or DelegateCallVoucher")
+
+	_, err = DecodeOutput(common.FromHex("0x1234"))
+	s.ErrorContains(err, "too short")
+}
+
+func (s *OutputSuite) TestItFailsOnTruncatedOutputs() {
+	rawData := s.pack("Voucher", testDestination, big.NewInt(1), testPayload)
+	_, err := DecodeOutput(rawData[:40])
+	s.ErrorContains(err, "failed to unpack Voucher")
+}
+
+// The migration that splits the outputs in the database
+const decodeOutputsMigration = "../../internal/repository/schema/migrations/000004_decode_outputs.up.sql"
+
+func (s *OutputSuite) TestItClassifiesOutputsAsTheDatabaseDoes() {
+	migration, err := os.ReadFile(decodeOutputsMigration)
+	s.Require().Nil(err)
+	selectors := regexp.MustCompile(`WHEN '\\x([0-9a-f]{8})'::BYTEA THEN '(\w+)'::"OutputType"`).
+		FindAllStringSubmatch(string(migration), -1)
+	s.Require().Len(selectors, len(outputMethods))
+
+	fixtures := map[OutputType][]byte{
+		OutputTypeVoucher:             s.pack("Voucher", testDestination, big.NewInt(1), testPayload),
+		OutputTypeNotice:              s.pack("Notice", testPayload),
+		OutputTypeDelegateCallVoucher: s.pack("DelegateCallVoucher", testDestination, testPayload),
+	}
+	for _, match := range selectors {
+		outputType := OutputType(match[2])
+		rawData := fixtures[outputType]
+		s.Require().NotNil(rawData, outputType)
+		s.Equal(match[1], common.Bytes2Hex(rawData[:4]), outputType)
+		output, err := DecodeOutput(rawData)
+		s.Require().Nil(err)
+		s.Equal(outputType, output.Type)
+	}
+}
@@ -0,0 +1,11 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// This package contains a Go client for the Espresso service API.
+// The InputSender builds, signs and submits inputs, using a private key or mnemonic Signer,
+// and waits for the Espresso Reader to ingest them.
+// The lower level client is generated automatically from api/openapi/espresso.yaml.
+// To regenerate it, run `go generate` from this directory.
+package espressoclient
+
+//go:generate go run github.com/deepmap/oapi-codegen/v2/cmd/oapi-codegen -config=oapi.yaml ../../api/openapi/espresso.yaml
@@ -0,0 +1,64 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Message is an input to an application, as signed by its sender
+type Message = typeddata.CartesiMessage
+
+// SignedMessage is a message and its signature, as read by the Espresso Reader
+type SignedMessage = typeddata.SigAndData
+
+// NewTypedData returns the EIP-712 typed data of a message to an application on the given chain
+func NewTypedData(chainId uint64, message Message) apitypes.TypedData {
+	return typeddata.New(chainId, message)
+}
+
+// SignMessage builds and signs the typed data of a message
+func SignMessage(signer Signer, chainId uint64, message Message) (*SignedMessage, error) {
+	typedData := NewTypedData(chainId, message)
+	if err := typeddata.Validate(typedData, chainId); err != nil {
+		return nil, err
+	}
+	signature, err := signer.SignTypedData(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+	return &SignedMessage{
+		TypedData: typedData,
+		Account:   signer.Address().Hex(),
+		Signature: hexutil.Encode(signature),
+	}, nil
+}
+
+// EncodePayload returns the Espresso transaction payload of a signed message,
+// for clients that submit to Espresso directly instead of through the service
+func EncodePayload(signed *SignedMessage) ([]byte, error) {
+	document, err := json.Marshal(signed)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(document)), nil
+}
+
+// TransactionIdOf returns the id the Espresso Reader gives to the input of a signed message,
+// which is the same id returned by the service on submission
+func TransactionIdOf(signed *SignedMessage) (string, error) {
+	signature, err := hexutil.Decode(signed.Signature)
+	if err != nil {
+		return "", fmt.Errorf("decode signature: %w", err)
+	}
+	return crypto.Keccak256Hash(signature).Hex(), nil
+}
@@ -0,0 +1,180 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// APIError is an error response of the Espresso service
+type APIError struct {
+	StatusCode int
+	// Machine-readable code, such as invalid_nonce
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("espresso service responded with %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("espresso service responded with %d (%s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+func newAPIError(statusCode int, body []byte) error {
+	var response ErrorResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Code == "" {
+		return &APIError{StatusCode: statusCode, Message: strings.TrimSpace(string(body))}
+	}
+	return &APIError{StatusCode: statusCode, Code: response.Code, Message: response.Message}
+}
+
+// InputSender builds, signs and submits inputs through the Espresso service
+type InputSender struct {
+	client ClientWithResponsesInterface
+	signer Signer
+	// MaxGasPrice of the signed messages, zero if nil
+	MaxGasPrice *big.Int
+	// How often WaitForInput polls the service
+	PollInterval time.Duration
+}
+
+// NewInputSender returns an InputSender that signs with the given signer
+// and talks to the Espresso service at serviceUrl
+func NewInputSender(serviceUrl string, signer Signer, opts ...ClientOption) (*InputSender, error) {
+	client, err := NewClientWithResponses(serviceUrl, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &InputSender{client: client, signer: signer, PollInterval: time.Second}, nil
+}
+
+// Address returns the address of the signer, which is the msg_sender of the inputs
+func (s *InputSender) Address() common.Address {
+	return s.signer.Address()
+}
+
+// Domain returns the typed data domain and the limits of the application
+func (s *InputSender) Domain(ctx context.Context, app common.Address) (*DomainInfo, error) {
+	response, err := s.client.GetDomainWithResponse(ctx, &GetDomainParams{App: app.Hex()})
+	if err != nil {
+		return nil, err
+	}
+	if response.JSON200 == nil {
+		return nil, newAPIError(response.StatusCode(), response.Body)
+	}
+	return response.JSON200, nil
+}
+
+// Nonce returns the nonce of the next input of the signer to the application
+func (s *InputSender) Nonce(ctx context.Context, app common.Address) (uint64, error) {
+	response, err := s.client.RequestNonceWithResponse(ctx, NonceRequest{
+		AppContract: app.Hex(),
+		MsgSender:   s.signer.Address().Hex(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if response.JSON200 == nil {
+		return 0, newAPIError(response.StatusCode(), response.Body)
+	}
+	return response.JSON200.Nonce, nil
+}
+
+// Sign builds and signs the next input of the signer to the application
+func (s *InputSender) Sign(ctx context.Context, app common.Address, data []byte) (*SignedMessage, error) {
+	domain, err := s.Domain(ctx, app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+	if uint64(len(data)) > domain.MaxPayloadSize {
+		return nil, fmt.Errorf("payload has %d bytes, the limit is %d", len(data), domain.MaxPayloadSize)
+	}
+	nonce, err := s.Nonce(ctx, app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	return SignMessage(s.signer, domain.ChainId, Message{
+		App:         app,
+		Nonce:       nonce,
+		MaxGasPrice: s.MaxGasPrice,
+		Data:        data,
+	})
+}
+
+// Submit sends a signed message to the Espresso service and returns its transaction id
+func (s *InputSender) Submit(ctx context.Context, signed *SignedMessage) (string, error) {
+	body, err := json.Marshal(signed)
+	if err != nil {
+		return "", err
+	}
+	response, err := s.client.SubmitTransactionWithBodyWithResponse(ctx,
+		"application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	if response.JSON200 == nil {
+		return "", newAPIError(response.StatusCode(), response.Body)
+	}
+	return response.JSON200.Id, nil
+}
+
+// Send signs and submits the next input of the signer to the application,
+// and returns its transaction id
+func (s *InputSender) Send(ctx context.Context, app common.Address, data []byte) (string, error) {
+	signed, err := s.Sign(ctx, app, data)
+	if err != nil {
+		return "", err
+	}
+	return s.Submit(ctx, signed)
+}
+
+// Status returns the status of a submitted transaction
+func (s *InputSender) Status(ctx context.Context, txId string) (*TransactionStatus, error) {
+	response, err := s.client.GetTransactionStatusWithResponse(ctx, txId)
+	if err != nil {
+		return nil, err
+	}
+	if response.JSON200 == nil {
+		return nil, newAPIError(response.StatusCode(), response.Body)
+	}
+	return response.JSON200, nil
+}
+
+// WaitForInput polls the status of a submitted transaction until the reader ingests it,
+// and returns the resulting input.
+// Transactions that are never sequenced are waited for until the context is canceled.
+func (s *InputSender) WaitForInput(ctx context.Context, txId string) (*Input, error) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := s.Status(ctx, txId)
+		if err != nil {
+			return nil, err
+		}
+		if status.Status == INGESTED && status.Input != nil {
+			return status.Input, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsAPIError reports whether err is an error response of the service with the given code
+func IsAPIError(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
@@ -0,0 +1,172 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZzzzHui/espresso-reader/internal/espressoreader/service"
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+const senderTestChainId = 31337
+
+var senderTestApp = common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+
+// fakeService implements the Espresso service API in memory
+type fakeService struct {
+	service.Unimplemented
+	mu        sync.Mutex
+	submitted []*typeddata.CartesiMessage
+	polls     int
+}
+
+func (f *fakeService) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func (f *fakeService) GetDomain(w http.ResponseWriter, r *http.Request, params service.GetDomainParams) {
+	if common.HexToAddress(params.App) != senderTestApp {
+		f.writeJSON(w, http.StatusNotFound, service.ErrorResponse{
+			Code: service.ErrCodeApplicationNotFound, Message: "unknown app"})
+		return
+	}
+	f.writeJSON(w, http.StatusOK, service.DomainInfo{
+		App:            params.App,
+		ChainId:        senderTestChainId,
+		MaxPayloadSize: 16,
+	})
+}
+
+func (f *fakeService) RequestNonce(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writeJSON(w, http.StatusOK, service.NonceResponse{Nonce: uint64(len(f.submitted))})
+}
+
+func (f *fakeService) SubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.writeJSON(w, http.StatusBadRequest, service.ErrorResponse{Code: service.ErrCodeBadRequest})
+		return
+	}
+	_, typedData, txId, err := typeddata.ExtractSigAndData(base64.StdEncoding.EncodeToString(body))
+	if err != nil {
+		f.writeJSON(w, http.StatusBadRequest, service.ErrorResponse{
+			Code: service.ErrCodeInvalidSignature, Message: err.Error()})
+		return
+	}
+	message, err := typeddata.ParseCartesiMessage(typedData.Message)
+	if err != nil {
+		f.writeJSON(w, http.StatusBadRequest, service.ErrorResponse{
+			Code: service.ErrCodeInvalidTypedData, Message: err.Error()})
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitted = append(f.submitted, message)
+	f.writeJSON(w, http.StatusOK, service.SubmitResponse{Id: txId})
+}
+
+func (f *fakeService) GetTransactionStatus(w http.ResponseWriter, r *http.Request, id service.TransactionId) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.polls++
+	status := service.TransactionStatus{Id: id, Status: service.UNKNOWN}
+	if f.polls >= 3 {
+		status.Status = service.INGESTED
+		status.Input = &service.Input{AppContract: senderTestApp.Hex(), Index: 5}
+	}
+	f.writeJSON(w, http.StatusOK, status)
+}
+
+type InputSenderSuite struct {
+	suite.Suite
+	ctx    context.Context
+	fake   *fakeService
+	server *httptest.Server
+	signer Signer
+	sender *InputSender
+}
+
+func TestInputSenderSuite(t *testing.T) {
+	suite.Run(t, new(InputSenderSuite))
+}
+
+func (s *InputSenderSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.fake = &fakeService{}
+	s.server = httptest.NewServer(service.Handler(s.fake))
+
+	var err error
+	s.signer, err = NewMnemonicSigner(testMnemonic, 0)
+	s.Require().Nil(err)
+	s.sender, err = NewInputSender(s.server.URL, s.signer)
+	s.Require().Nil(err)
+	s.sender.PollInterval = time.Millisecond
+}
+
+func (s *InputSenderSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *InputSenderSuite) TestSend() {
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		txId, err := s.sender.Send(s.ctx, senderTestApp, []byte("hello"))
+		s.Require().Nil(err)
+		s.NotEmpty(txId)
+	}
+	s.Require().Len(s.fake.submitted, 2)
+	for nonce, message := range s.fake.submitted {
+		s.Equal(senderTestApp, message.App)
+		s.Equal(uint64(nonce), message.Nonce)
+		s.Equal([]byte("hello"), message.Data)
+	}
+}
+
+func (s *InputSenderSuite) TestSendChecksThePayloadSize() {
+	_, err := s.sender.Send(s.ctx, senderTestApp, make([]byte, 17))
+	s.ErrorContains(err, "the limit is 16")
+	s.Empty(s.fake.submitted)
+}
+
+func (s *InputSenderSuite) TestItReturnsAPIErrors() {
+	_, err := s.sender.Send(s.ctx, common.HexToAddress("0xdeadbeef"), []byte("hello"))
+	s.True(IsAPIError(err, service.ErrCodeApplicationNotFound), err)
+
+	var apiErr *APIError
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusNotFound, apiErr.StatusCode)
+}
+
+func (s *InputSenderSuite) TestWaitForInput() {
+	txId, err := s.sender.Send(s.ctx, senderTestApp, []byte("hello"))
+	s.Require().Nil(err)
+
+	input, err := s.sender.WaitForInput(s.ctx, txId)
+	s.Require().Nil(err)
+	s.Equal(uint64(5), input.Index)
+	s.Equal(3, s.fake.polls)
+}
+
+func (s *InputSenderSuite) TestWaitForInputStopsWithTheContext() {
+	ctx, cancel := context.WithTimeout(s.ctx, 0)
+	defer cancel()
+	s.fake.polls = -100
+	_, err := s.sender.WaitForInput(ctx, common.Hash{}.Hex())
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
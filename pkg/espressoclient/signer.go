@@ -0,0 +1,84 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoclient
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Signer signs EIP-712 typed data on behalf of an account
+type Signer interface {
+	Address() common.Address
+	// SignTypedData returns a 65-byte signature with a recovery id of 27 or 28,
+	// as returned by eth_signTypedData_v4
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
+
+type privateKeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewPrivateKeySigner returns a Signer for the hex-encoded private key
+func NewPrivateKeySigner(privateKey string) (Signer, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return &privateKeySigner{key: key}, nil
+}
+
+// NewMnemonicSigner returns a Signer for the account at m/44'/60'/0'/0/accountIndex,
+// the derivation path used by most Ethereum wallets
+func NewMnemonicSigner(mnemonic string, accountIndex uint32) (Signer, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(strings.TrimSpace(mnemonic), "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	key, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive master key: %w", err)
+	}
+	for _, index := range []uint32{
+		bip32.FirstHardenedChild + 44,
+		bip32.FirstHardenedChild + 60,
+		bip32.FirstHardenedChild + 0,
+		0,
+		accountIndex,
+	} {
+		key, err = key.NewChildKey(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive child key: %w", err)
+		}
+	}
+	privateKey, err := crypto.ToECDSA(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derived key: %w", err)
+	}
+	return &privateKeySigner{key: privateKey}, nil
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *privateKeySigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("typed data hash: %w", err)
+	}
+	signature, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
@@ -0,0 +1,76 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package espressoclient
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/ZzzzHui/espresso-reader/pkg/typeddata"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/suite"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+type SignerSuite struct {
+	suite.Suite
+}
+
+func TestSignerSuite(t *testing.T) {
+	suite.Run(t, new(SignerSuite))
+}
+
+func (s *SignerSuite) TestMnemonicSigner() {
+	// the well-known development accounts of Anvil and Hardhat
+	for index, address := range []string{
+		"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		"0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+	} {
+		signer, err := NewMnemonicSigner(testMnemonic, uint32(index))
+		s.Require().Nil(err)
+		s.Equal(common.HexToAddress(address), signer.Address())
+	}
+
+	_, err := NewMnemonicSigner("not a mnemonic", 0)
+	s.Error(err)
+}
+
+func (s *SignerSuite) TestPrivateKeySigner() {
+	signer, err := NewPrivateKeySigner(
+		"0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	s.Require().Nil(err)
+	s.Equal(common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), signer.Address())
+
+	_, err = NewPrivateKeySigner("0x1234")
+	s.Error(err)
+}
+
+func (s *SignerSuite) TestSignedMessagesAreReadByTheReader() {
+	signer, err := NewMnemonicSigner(testMnemonic, 0)
+	s.Require().Nil(err)
+	app := common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E")
+
+	signed, err := SignMessage(signer, 31337, Message{App: app, Nonce: 3, Data: []byte("hello")})
+	s.Require().Nil(err)
+	payload, err := EncodePayload(signed)
+	s.Require().Nil(err)
+	_, err = base64.StdEncoding.DecodeString(string(payload))
+	s.Require().Nil(err)
+
+	sender, typedData, txId, err := typeddata.ExtractSigAndData(string(payload))
+	s.Require().Nil(err)
+	expectedTxId, err := TransactionIdOf(signed)
+	s.Require().Nil(err)
+	s.Equal(expectedTxId, txId)
+	s.Equal(signer.Address(), sender)
+	s.Nil(typeddata.Validate(typedData, 31337))
+	message, err := typeddata.ParseCartesiMessage(typedData.Message)
+	s.Require().Nil(err)
+	s.Equal(app, message.App)
+	s.Equal(uint64(3), message.Nonce)
+	s.Equal(uint64(0), message.MaxGasPrice.Uint64())
+	s.Equal([]byte("hello"), message.Data)
+}
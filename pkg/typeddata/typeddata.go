@@ -0,0 +1,167 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package typeddata holds the EIP-712 typed data of the inputs sent through Espresso:
+// its schema, how to build and validate it, and how to recover the sender of a signed one.
+// Both the Espresso Reader and its clients use it.
+package typeddata
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EIP-712 domain and primary type of the messages signed by Espresso input senders
+const (
+	DomainName    = "Cartesi"
+	DomainVersion = "0.1.0"
+	PrimaryType   = "CartesiMessage"
+)
+
+// Types are the EIP-712 types of the messages signed by Espresso input senders
+var Types = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	PrimaryType: {
+		{Name: "app", Type: "address"},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "max_gas_price", Type: "uint128"},
+		{Name: "data", Type: "bytes"},
+	},
+}
+
+// CartesiMessage is the decoded message of an Espresso input
+type CartesiMessage struct {
+	App         common.Address
+	Nonce       uint64
+	MaxGasPrice *big.Int
+	Data        []byte
+}
+
+// New returns the EIP-712 typed data of a message to an application on the given chain.
+// The integers of the message are encoded as decimal strings, which keep their precision
+// through JSON, unlike numbers.
+func New(chainId uint64, message CartesiMessage) apitypes.TypedData {
+	types := apitypes.Types{}
+	for name, fields := range Types {
+		types[name] = append([]apitypes.Type{}, fields...)
+	}
+	maxGasPrice := message.MaxGasPrice
+	if maxGasPrice == nil {
+		maxGasPrice = new(big.Int)
+	}
+	return apitypes.TypedData{
+		Types:       types,
+		PrimaryType: PrimaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              DomainName,
+			Version:           DomainVersion,
+			ChainId:           (*math.HexOrDecimal256)(new(big.Int).SetUint64(chainId)),
+			VerifyingContract: common.Address{}.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"app":           message.App.Hex(),
+			"nonce":         strconv.FormatUint(message.Nonce, 10),
+			"max_gas_price": maxGasPrice.String(),
+			"data":          hexutil.Encode(message.Data),
+		},
+	}
+}
+
+// Validate checks that the typed data follows the schema expected by the reader
+// and that it was signed for the given chain
+func Validate(typedData apitypes.TypedData, chainId uint64) error {
+	if typedData.PrimaryType != PrimaryType {
+		return fmt.Errorf("primary type must be %s, got '%s'",
+			PrimaryType, typedData.PrimaryType)
+	}
+	if !slices.Equal(typedData.Types[PrimaryType], Types[PrimaryType]) {
+		return fmt.Errorf("type %s does not match the expected fields %v",
+			PrimaryType, Types[PrimaryType])
+	}
+
+	domain := typedData.Domain
+	if domain.Name != DomainName {
+		return fmt.Errorf("domain name must be '%s', got '%s'", DomainName, domain.Name)
+	}
+	if domain.Version != DomainVersion {
+		return fmt.Errorf("domain version must be '%s', got '%s'", DomainVersion, domain.Version)
+	}
+	if domain.ChainId == nil {
+		return fmt.Errorf("domain chain id is missing")
+	}
+	if (*big.Int)(domain.ChainId).Cmp(new(big.Int).SetUint64(chainId)) != 0 {
+		return fmt.Errorf("domain chain id must be %d, got %s", chainId, (*big.Int)(domain.ChainId))
+	}
+	return nil
+}
+
+// ParseCartesiMessage decodes the message of a typed data following the Types schema
+func ParseCartesiMessage(message apitypes.TypedDataMessage) (*CartesiMessage, error) {
+	app, ok := message["app"].(string)
+	if !ok || !common.IsHexAddress(app) {
+		return nil, fmt.Errorf("app must be an address, got '%v'", message["app"])
+	}
+
+	nonce, err := parseUint(message["nonce"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+
+	maxGasPrice, err := parseUint(message["max_gas_price"], 128)
+	if err != nil {
+		return nil, fmt.Errorf("max_gas_price: %w", err)
+	}
+
+	dataStr, ok := message["data"].(string)
+	if !ok {
+		return nil, fmt.Errorf("data must be a hex string, got '%v'", message["data"])
+	}
+	data, err := hexutil.Decode(dataStr)
+	if err != nil {
+		return nil, fmt.Errorf("data: %w", err)
+	}
+
+	return &CartesiMessage{
+		App:         common.HexToAddress(app),
+		Nonce:       nonce.Uint64(),
+		MaxGasPrice: maxGasPrice,
+		Data:        data,
+	}, nil
+}
+
+// parseUint parses an unsigned integer the same way the EIP-712 encoder does:
+// JSON numbers are decoded as float64, while strings may be decimal or hex
+func parseUint(value any, bits int) (*big.Int, error) {
+	var n *big.Int
+	switch v := value.(type) {
+	case float64:
+		if v != float64(uint64(v)) {
+			return nil, fmt.Errorf("invalid integer value %v", v)
+		}
+		n = new(big.Int).SetUint64(uint64(v))
+	case string:
+		var parsed math.HexOrDecimal256
+		if err := parsed.UnmarshalText([]byte(v)); err != nil {
+			return nil, err
+		}
+		n = (*big.Int)(&parsed)
+	default:
+		return nil, fmt.Errorf("invalid integer value %v", value)
+	}
+	if n.Sign() < 0 || n.BitLen() > bits {
+		return nil, fmt.Errorf("value %s out of range for uint%d", n, bits)
+	}
+	return n, nil
+}
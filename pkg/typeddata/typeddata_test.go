@@ -0,0 +1,86 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+package typeddata
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/suite"
+)
+
+type TypedDataSuite struct {
+	suite.Suite
+}
+
+func TestTypedDataSuite(t *testing.T) {
+	suite.Run(t, new(TypedDataSuite))
+}
+
+func (s *TypedDataSuite) message() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"app":           "0x2E663fe9aE92275242406A185AA4fC8174339D3E",
+		"nonce":         float64(3),
+		"max_gas_price": float64(0),
+		"data":          "0xdeadbeef",
+	}
+}
+
+func (s *TypedDataSuite) TestItParsesTheMessage() {
+	parsed, err := ParseCartesiMessage(s.message())
+	s.Require().Nil(err)
+	s.Equal(common.HexToAddress("0x2E663fe9aE92275242406A185AA4fC8174339D3E"), parsed.App)
+	s.Equal(uint64(3), parsed.Nonce)
+	s.Equal(big.NewInt(0), parsed.MaxGasPrice)
+	s.Equal([]byte{0xde, 0xad, 0xbe, 0xef}, parsed.Data)
+}
+
+func (s *TypedDataSuite) TestItParsesNoncesGivenAsStrings() {
+	for nonce, expected := range map[string]uint64{
+		"16":                   16,
+		"0x10":                 16,
+		"18446744073709551615": 18446744073709551615,
+	} {
+		message := s.message()
+		message["nonce"] = nonce
+		parsed, err := ParseCartesiMessage(message)
+		s.Require().Nil(err, nonce)
+		s.Equal(expected, parsed.Nonce, nonce)
+	}
+}
+
+func (s *TypedDataSuite) TestItEncodesNoncesAsDecimalStrings() {
+	message := CartesiMessage{
+		App:         common.HexToAddress("0x5112cF49F2511ac7b13A032c4c62A48410FC28Fb"),
+		Nonce:       1<<53 + 1,
+		Data:        []byte{0xde, 0xad},
+		MaxGasPrice: big.NewInt(10),
+	}
+	typedData := New(31337, message)
+	s.Equal("9007199254740993", typedData.Message["nonce"])
+	s.Nil(Validate(typedData, 31337))
+	parsed, err := ParseCartesiMessage(typedData.Message)
+	s.Require().Nil(err)
+	s.Equal(message.Nonce, parsed.Nonce)
+}
+
+func (s *TypedDataSuite) TestItRejectsMalformedMessages() {
+	for field, value := range map[string]any{
+		"app":   float64(1),
+		"nonce": "18446744073709551616",
+		"data":  []byte{0x01},
+	} {
+		message := s.message()
+		message[field] = value
+		_, err := ParseCartesiMessage(message)
+		s.ErrorContains(err, field)
+	}
+
+	message := s.message()
+	delete(message, "nonce")
+	_, err := ParseCartesiMessage(message)
+	s.ErrorContains(err, "nonce")
+}
@@ -0,0 +1,47 @@
+// (c) Cartesi and individual authors (see AUTHORS)
+// SPDX-License-Identifier: Apache-2.0 (see LICENSE)
+
+// Package db sets up the Postgres database of the tests that need one.
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ZzzzHui/espresso-reader/internal/repository/schema"
+)
+
+// Variable with the endpoint of the test database, as exported by `make env`
+const testEndpointVariable = "CARTESI_TEST_POSTGRES_ENDPOINT"
+
+// ErrNoTestDatabase is returned when no test database is configured,
+// so the tests that need one can be skipped
+var ErrNoTestDatabase = errors.New(testEndpointVariable + " is not set")
+
+// GetPostgresTestEndpoint returns the endpoint of the test database
+func GetPostgresTestEndpoint() (string, error) {
+	endpoint := os.Getenv(testEndpointVariable)
+	if endpoint == "" {
+		return "", ErrNoTestDatabase
+	}
+	return endpoint, nil
+}
+
+// SetupTestPostgres drops every table of the test database,
+// then migrates it to the schema the node expects
+func SetupTestPostgres(endpoint string) error {
+	schema, err := schema.New(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to open the test database: %w", err)
+	}
+	defer schema.Close()
+
+	if err := schema.Downgrade(); err != nil {
+		return fmt.Errorf("failed to reset the test database: %w", err)
+	}
+	if err := schema.Upgrade(); err != nil {
+		return fmt.Errorf("failed to migrate the test database: %w", err)
+	}
+	return nil
+}